@@ -0,0 +1,257 @@
+package sonos
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Alarm is an alarm set in the Sonos app.
+type Alarm struct {
+	ID         int
+	StartTime  time.Duration // local time of day, as an offset from midnight
+	Duration   time.Duration // how long to play for, or zero for no limit
+	Recurrence string        // e.g. "DAILY", "WEEKDAYS", "ONCE" or "ON_135" (days from Sunday=0)
+	Enabled    bool
+
+	RoomUUID           string // UUID of the device that plays the alarm
+	IncludeLinkedZones bool   // whether the zones grouped with the room also play it
+
+	ProgramURI      string // what to play; "x-rincon-buzzer:0" is the Sonos chime
+	ProgramMetadata string // DIDL-Lite XML, often empty
+	PlayMode        PlayMode
+	Volume          int
+}
+
+// Alarms returns the household's alarms.
+func (c *Client) Alarms(ctx context.Context) ([]Alarm, error) {
+	d, err := c.alarmClock()
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		CurrentAlarmList        string // XML
+		CurrentAlarmListVersion string
+	}
+	if err := d.soap(ctx, AlarmClockService, "ListAlarms", struct{}{}, &resp); err != nil {
+		return nil, fmt.Errorf("listing alarms: %w", err)
+	}
+	var list struct {
+		Alarms []struct {
+			ID                 string `xml:",attr"`
+			StartTime          string `xml:",attr"` // "HH:MM:SS"
+			Duration           string `xml:",attr"` // "HH:MM:SS"
+			Recurrence         string `xml:",attr"`
+			Enabled            string `xml:",attr"`
+			RoomUUID           string `xml:",attr"`
+			ProgramURI         string `xml:",attr"`
+			ProgramMetaData    string `xml:",attr"`
+			PlayMode           string `xml:",attr"`
+			Volume             string `xml:",attr"`
+			IncludeLinkedZones string `xml:",attr"`
+		} `xml:"Alarm"`
+	}
+	if err := xml.Unmarshal([]byte(resp.CurrentAlarmList), &list); err != nil {
+		return nil, fmt.Errorf("parsing alarm list: %w", err)
+	}
+	var alarms []Alarm
+	for _, a := range list.Alarms {
+		alarm := Alarm{
+			StartTime:          parseHMS(a.StartTime),
+			Duration:           parseHMS(a.Duration),
+			Recurrence:         a.Recurrence,
+			Enabled:            a.Enabled == "1",
+			RoomUUID:           a.RoomUUID,
+			IncludeLinkedZones: a.IncludeLinkedZones == "1",
+			ProgramURI:         a.ProgramURI,
+			ProgramMetadata:    a.ProgramMetaData,
+		}
+		for mode, id := range playModeIDs {
+			if id == a.PlayMode {
+				alarm.PlayMode = mode
+			}
+		}
+		if alarm.ID, err = strconv.Atoi(a.ID); err != nil {
+			return nil, fmt.Errorf("parsing alarm ID %q: %w", a.ID, err)
+		}
+		alarm.Volume, _ = strconv.Atoi(a.Volume)
+		alarms = append(alarms, alarm)
+	}
+	return alarms, nil
+}
+
+// CreateAlarm adds an alarm to the household, returning its ID.
+// The alarm's own ID is ignored.
+// Its volume must be in range [0,100], and is limited to the maximum volume
+// of the device that plays it.
+func (c *Client) CreateAlarm(ctx context.Context, a Alarm) (int, error) {
+	d, err := c.alarmClock()
+	if err != nil {
+		return 0, err
+	}
+	volume, err := c.alarmVolume(a)
+	if err != nil {
+		return 0, err
+	}
+	var resp struct {
+		AssignedID string // ui4
+	}
+	err = d.soap(ctx, AlarmClockService, "CreateAlarm", struct {
+		StartLocalTime     string
+		Duration           string
+		Recurrence         string
+		Enabled            string
+		RoomUUID           string
+		ProgramURI         string
+		ProgramMetaData    string
+		PlayMode           string
+		Volume             string
+		IncludeLinkedZones string
+	}{
+		StartLocalTime:     alarmTime(a.StartTime),
+		Duration:           alarmTime(a.Duration),
+		Recurrence:         a.Recurrence,
+		Enabled:            boolString(a.Enabled),
+		RoomUUID:           a.RoomUUID,
+		ProgramURI:         a.ProgramURI,
+		ProgramMetaData:    a.ProgramMetadata,
+		PlayMode:           playModeIDs[a.PlayMode],
+		Volume:             strconv.Itoa(volume),
+		IncludeLinkedZones: boolString(a.IncludeLinkedZones),
+	}, &resp)
+	if err != nil {
+		return 0, fmt.Errorf("creating alarm: %w", err)
+	}
+	id, err := strconv.Atoi(resp.AssignedID)
+	if err != nil {
+		return 0, fmt.Errorf("parsing alarm ID %q: %w", resp.AssignedID, err)
+	}
+	return id, nil
+}
+
+// alarmVolume returns the volume that the alarm will play at,
+// limited by the ceiling of its room's device.
+func (c *Client) alarmVolume(a Alarm) (int, error) {
+	if a.Volume < 0 || a.Volume > 100 {
+		return 0, fmt.Errorf("alarm volume %d out of range [0,100]", a.Volume)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return min(a.Volume, c.ceiling("uuid:"+a.RoomUUID)), nil
+}
+
+// alarmClock returns a device that provides the household's alarm clock.
+func (c *Client) alarmClock() (*Device, error) {
+	for _, d := range c.Devices() {
+		if len(d.dev.FindService(AlarmClockService)) > 0 {
+			return d, nil
+		}
+	}
+	return nil, errors.New("no device provides an alarm clock")
+}
+
+// alarmTime formats a time of day or duration in the form "HH:MM:SS".
+func alarmTime(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+// TimeNow returns the device's clock, in a fixed time zone
+// with the offset of the device's configured time zone.
+func (d *Device) TimeNow(ctx context.Context) (time.Time, error) {
+	var resp struct {
+		CurrentUTCTime        string // "2006-01-02 15:04:05"
+		CurrentLocalTime      string
+		CurrentTimeZone       string // an opaque rule
+		CurrentTimeGeneration string
+	}
+	if err := d.soap(ctx, AlarmClockService, "GetTimeNow", struct{}{}, &resp); err != nil {
+		return time.Time{}, fmt.Errorf("getting time: %w", err)
+	}
+	const layout = "2006-01-02 15:04:05"
+	utc, err := time.Parse(layout, resp.CurrentUTCTime)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing UTC time: %w", err)
+	}
+	local, err := time.Parse(layout, resp.CurrentLocalTime)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing local time: %w", err)
+	}
+	offset := local.Sub(utc).Round(15 * time.Minute)
+	return utc.In(time.FixedZone("", int(offset.Seconds()))), nil
+}
+
+// TimeZone identifies one of the time zones that devices offer,
+// as listed in the Sonos app.
+type TimeZone struct {
+	Index         int  // position in the Sonos app's list of time zones
+	AutoAdjustDST bool // whether to follow daylight saving time
+}
+
+// TimeZone returns the device's time zone.
+func (d *Device) TimeZone(ctx context.Context) (TimeZone, error) {
+	var resp struct {
+		Index         string // i4
+		AutoAdjustDst string // bool
+	}
+	if err := d.soap(ctx, AlarmClockService, "GetTimeZone", struct{}{}, &resp); err != nil {
+		return TimeZone{}, fmt.Errorf("getting time zone: %w", err)
+	}
+	tz := TimeZone{AutoAdjustDST: resp.AutoAdjustDst == "1"}
+	var err error
+	if tz.Index, err = strconv.Atoi(resp.Index); err != nil {
+		return TimeZone{}, fmt.Errorf("parsing time zone index %q: %w", resp.Index, err)
+	}
+	return tz, nil
+}
+
+// SetTimeZone sets the device's time zone, which determines when alarms go off.
+// The household's devices share a time zone.
+func (d *Device) SetTimeZone(ctx context.Context, tz TimeZone) error {
+	err := d.soap(ctx, AlarmClockService, "SetTimeZone", struct {
+		Index         string
+		AutoAdjustDst string
+	}{
+		Index:         strconv.Itoa(tz.Index),
+		AutoAdjustDst: boolString(tz.AutoAdjustDST),
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting time zone: %w", err)
+	}
+	return nil
+}
+
+// DailyIndexRefreshTime returns the local time of day at which the household's
+// music library is reindexed, as an offset from midnight.
+func (c *Client) DailyIndexRefreshTime(ctx context.Context) (time.Duration, error) {
+	d, err := c.alarmClock()
+	if err != nil {
+		return 0, err
+	}
+	var resp struct {
+		CurrentDailyIndexRefreshTime string // "HH:MM:SS"
+	}
+	if err := d.soap(ctx, AlarmClockService, "GetDailyIndexRefreshTime", struct{}{}, &resp); err != nil {
+		return 0, fmt.Errorf("getting daily index refresh time: %w", err)
+	}
+	return parseHMS(resp.CurrentDailyIndexRefreshTime), nil
+}
+
+// SetDailyIndexRefreshTime sets the local time of day at which the household's
+// music library is reindexed, as an offset from midnight.
+func (c *Client) SetDailyIndexRefreshTime(ctx context.Context, at time.Duration) error {
+	d, err := c.alarmClock()
+	if err != nil {
+		return err
+	}
+	err = d.soap(ctx, AlarmClockService, "SetDailyIndexRefreshTime", struct {
+		DesiredDailyIndexRefreshTime string
+	}{DesiredDailyIndexRefreshTime: alarmTime(at % (24 * time.Hour))}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting daily index refresh time: %w", err)
+	}
+	return nil
+}
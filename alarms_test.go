@@ -0,0 +1,33 @@
+package sonos
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCreateAlarmVolume(t *testing.T) {
+	ctx := context.Background()
+	fake := newFake(t, "Bedroom")
+	fake.Handle("CreateAlarm", func(map[string]string) (map[string]string, error) {
+		return map[string]string{"AssignedID": "7"}, nil
+	})
+	c := newTestClient(t, nil, fake)
+	c.LimitVolume(device(t, c, fake), 40)
+
+	a := Alarm{StartTime: 7 * time.Hour, Recurrence: "DAILY", RoomUUID: fake.UUID, ProgramURI: "x-rincon-buzzer:0", Volume: 60}
+	if _, err := c.CreateAlarm(ctx, a); err != nil {
+		t.Fatalf("CreateAlarm: %v", err)
+	}
+	calls := fake.Calls()
+	if got := calls[len(calls)-1].Args["Volume"]; got != "40" {
+		t.Errorf("CreateAlarm with volume 60 sent %s, want 40", got)
+	}
+
+	for _, vol := range []int{-1, 101} {
+		a.Volume = vol
+		if _, err := c.CreateAlarm(ctx, a); err == nil {
+			t.Errorf("CreateAlarm with volume %d succeeded, want an error", vol)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// AlbumArtURL resolves an album art URI from track metadata (e.g. PositionInfo.AlbumArtURI)
+// to an absolute URL. Such URIs are usually relative to the device's HTTP server,
+// which fetches and caches the image from wherever the track came from.
+func (d *Device) AlbumArtURL(ref string) (string, error) {
+	if ref == "" {
+		return "", errors.New("no album art")
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("parsing album art URI: %w", err)
+	}
+	if u.IsAbs() {
+		return u.String(), nil
+	}
+	base, err := d.baseURL()
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(u).String(), nil
+}
+
+// maxAlbumArtSize bounds the size of album art that AlbumArt will fetch;
+// larger images are an error rather than being truncated.
+const maxAlbumArtSize = 10 << 20
+
+// AlbumArt fetches the album art from track metadata (see AlbumArtURL).
+// It returns the image and its MIME type.
+func (d *Device) AlbumArt(ctx context.Context, ref string) (img []byte, contentType string, err error) {
+	u, err := d.AlbumArtURL(ref)
+	if err != nil {
+		return nil, "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := d.httpClient().Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetching album art: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("fetching album art from %s: %s", u, resp.Status)
+	}
+	img, err = io.ReadAll(io.LimitReader(resp.Body, maxAlbumArtSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("reading album art: %w", err)
+	}
+	if len(img) > maxAlbumArtSize {
+		return nil, "", fmt.Errorf("album art from %s is larger than %d bytes", u, maxAlbumArtSize)
+	}
+	contentType = resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(img)
+	}
+	return img, contentType, nil
+}
@@ -0,0 +1,30 @@
+package sonos
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAlbumArtTooLarge(t *testing.T) {
+	for _, size := range []int{maxAlbumArtSize, maxAlbumArtSize + 1} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Write(bytes.Repeat([]byte{0xff}, size))
+		}))
+		defer srv.Close()
+		fake := newFake(t, "Kitchen")
+		c := newTestClient(t, nil, fake)
+
+		img, _, err := device(t, c, fake).AlbumArt(context.Background(), srv.URL+"/art.jpg")
+		if size > maxAlbumArtSize {
+			if err == nil {
+				t.Errorf("AlbumArt of %d bytes returned %d bytes, want an error", size, len(img))
+			}
+		} else if err != nil || len(img) != size {
+			t.Errorf("AlbumArt of %d bytes returned %d bytes, %v", size, len(img), err)
+		}
+	}
+}
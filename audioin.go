@@ -0,0 +1,203 @@
+package sonos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// autoplaySource is the Source argument for the DeviceProperties autoplay actions.
+// The line-in is the only source this package deals with, and it is identified by an empty string.
+const autoplaySource = ""
+
+// LineInName returns the name of the device's line-in source, as shown in the Sonos app.
+func (d *Device) LineInName(ctx context.Context) (string, error) {
+	var resp struct {
+		CurrentName string
+		CurrentIcon string
+	}
+	err := d.soap(ctx, AudioInService, "GetAudioInputAttributes", struct{}{}, &resp)
+	if err != nil {
+		return "", fmt.Errorf("getting line-in attributes: %w", err)
+	}
+	return resp.CurrentName, nil
+}
+
+// SetLineInName sets the name of the device's line-in source.
+func (d *Device) SetLineInName(ctx context.Context, name string) error {
+	var attrs struct {
+		CurrentName string
+		CurrentIcon string
+	}
+	err := d.soap(ctx, AudioInService, "GetAudioInputAttributes", struct{}{}, &attrs)
+	if err != nil {
+		return fmt.Errorf("getting line-in attributes: %w", err)
+	}
+	err = d.soap(ctx, AudioInService, "SetAudioInputAttributes", struct {
+		DesiredName string
+		DesiredIcon string
+	}{
+		DesiredName: name,
+		DesiredIcon: attrs.CurrentIcon, // preserve
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting line-in attributes: %w", err)
+	}
+	return nil
+}
+
+// LineInLevel returns the device's line-in source level, in range [1,10].
+func (d *Device) LineInLevel(ctx context.Context) (int, error) {
+	var resp struct {
+		CurrentLeftLineInLevel  string // i4
+		CurrentRightLineInLevel string // i4
+	}
+	err := d.soap(ctx, AudioInService, "GetLineInLevel", struct{}{}, &resp)
+	if err != nil {
+		return 0, fmt.Errorf("getting line-in level: %w", err)
+	}
+	// The Sonos app always sets both channels together.
+	level, err := strconv.Atoi(resp.CurrentLeftLineInLevel)
+	if err != nil {
+		return 0, fmt.Errorf("parsing line-in level %q: %w", resp.CurrentLeftLineInLevel, err)
+	}
+	return level, nil
+}
+
+// SetLineInLevel sets the device's line-in source level, in range [1,10].
+// Higher levels suit quieter sources.
+func (d *Device) SetLineInLevel(ctx context.Context, level int) error {
+	err := d.soap(ctx, AudioInService, "SetLineInLevel", struct {
+		DesiredLeftLineInLevel  string
+		DesiredRightLineInLevel string
+	}{
+		DesiredLeftLineInLevel:  strconv.Itoa(level),
+		DesiredRightLineInLevel: strconv.Itoa(level),
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting line-in level: %w", err)
+	}
+	return nil
+}
+
+// AutoplayRoom returns the UUID of the room that starts playing the device's line-in
+// when a signal is detected. An empty string means autoplay is disabled.
+func (d *Device) AutoplayRoom(ctx context.Context) (string, error) {
+	var resp struct {
+		RoomUUID string
+	}
+	err := d.soap(ctx, DevicePropertiesService, "GetAutoplayRoomUUID", struct {
+		Source string
+	}{Source: autoplaySource}, &resp)
+	if err != nil {
+		return "", fmt.Errorf("getting autoplay room: %w", err)
+	}
+	return resp.RoomUUID, nil
+}
+
+// SetAutoplayRoom sets the room that starts playing the device's line-in
+// when a signal is detected. A nil room disables autoplay.
+func (d *Device) SetAutoplayRoom(ctx context.Context, room *Device) error {
+	var uuid string
+	if room != nil {
+		uuid = room.uid()
+	}
+	err := d.soap(ctx, DevicePropertiesService, "SetAutoplayRoomUUID", struct {
+		RoomUUID string
+		Source   string
+	}{
+		RoomUUID: uuid,
+		Source:   autoplaySource,
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting autoplay room: %w", err)
+	}
+	return nil
+}
+
+// AutoplayLinkedZones reports whether the zones grouped with the autoplay room
+// also play the device's line-in when a signal is detected.
+func (d *Device) AutoplayLinkedZones(ctx context.Context) (bool, error) {
+	var resp struct {
+		IncludeLinkedZones string // bool
+	}
+	err := d.soap(ctx, DevicePropertiesService, "GetAutoplayLinkedZones", struct {
+		Source string
+	}{Source: autoplaySource}, &resp)
+	if err != nil {
+		return false, fmt.Errorf("getting autoplay linked zones: %w", err)
+	}
+	return resp.IncludeLinkedZones == "1", nil
+}
+
+// SetAutoplayLinkedZones sets whether the zones grouped with the autoplay room
+// also play the device's line-in when a signal is detected.
+func (d *Device) SetAutoplayLinkedZones(ctx context.Context, include bool) error {
+	err := d.soap(ctx, DevicePropertiesService, "SetAutoplayLinkedZones", struct {
+		IncludeLinkedZones string
+		Source             string
+	}{
+		IncludeLinkedZones: boolString(include),
+		Source:             autoplaySource,
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting autoplay linked zones: %w", err)
+	}
+	return nil
+}
+
+// AutoplayVolume reports the volume that autoplay starts at, in range [0,100],
+// and whether that volume will be used at all.
+func (d *Device) AutoplayVolume(ctx context.Context) (volume int, use bool, err error) {
+	var vresp struct {
+		CurrentVolume string // ui2
+	}
+	err = d.soap(ctx, DevicePropertiesService, "GetAutoplayVolume", struct {
+		Source string
+	}{Source: autoplaySource}, &vresp)
+	if err != nil {
+		return 0, false, fmt.Errorf("getting autoplay volume: %w", err)
+	}
+	volume, err = strconv.Atoi(vresp.CurrentVolume)
+	if err != nil {
+		return 0, false, fmt.Errorf("parsing autoplay volume %q: %w", vresp.CurrentVolume, err)
+	}
+
+	var uresp struct {
+		UseVolume string // bool
+	}
+	err = d.soap(ctx, DevicePropertiesService, "GetUseAutoplayVolume", struct {
+		Source string
+	}{Source: autoplaySource}, &uresp)
+	if err != nil {
+		return 0, false, fmt.Errorf("getting use of autoplay volume: %w", err)
+	}
+	return volume, uresp.UseVolume == "1", nil
+}
+
+// SetAutoplayVolume sets the volume that autoplay starts at, in range [0,100].
+// Like SetVolume, it is limited to the device's maximum volume.
+// If use is false, autoplay leaves the volume unchanged.
+func (d *Device) SetAutoplayVolume(ctx context.Context, volume int, use bool) error {
+	err := d.soap(ctx, DevicePropertiesService, "SetAutoplayVolume", struct {
+		Volume string
+		Source string
+	}{
+		Volume: strconv.Itoa(d.clampVolume(volume)),
+		Source: autoplaySource,
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting autoplay volume: %w", err)
+	}
+	err = d.soap(ctx, DevicePropertiesService, "SetUseAutoplayVolume", struct {
+		UseVolume string
+		Source    string
+	}{
+		UseVolume: boolString(use),
+		Source:    autoplaySource,
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting use of autoplay volume: %w", err)
+	}
+	return nil
+}
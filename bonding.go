@@ -0,0 +1,81 @@
+package sonos
+
+import (
+	"context"
+	"fmt"
+)
+
+// stereoPairChannelMap returns the ChannelMapSet describing a stereo pair.
+func stereoPairChannelMap(left, right *Device) string {
+	return left.uid() + ":LF,LF;" + right.uid() + ":RF,RF"
+}
+
+// CreateStereoPair bonds two devices of the same model into a stereo pair.
+// The pair takes on the zone of the left device, which becomes its coordinator.
+func (c *Client) CreateStereoPair(ctx context.Context, left, right *Device) error {
+	err := left.soap(ctx, DevicePropertiesService, "CreateStereoPair", struct {
+		ChannelMapSet string
+	}{ChannelMapSet: stereoPairChannelMap(left, right)}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("creating stereo pair: %w", err)
+	}
+	return c.refreshZone(ctx, right)
+}
+
+// SeparateStereoPair splits a stereo pair created by CreateStereoPair.
+func (c *Client) SeparateStereoPair(ctx context.Context, left, right *Device) error {
+	err := left.soap(ctx, DevicePropertiesService, "SeparateStereoPair", struct {
+		ChannelMapSet string
+	}{ChannelMapSet: stereoPairChannelMap(left, right)}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("separating stereo pair: %w", err)
+	}
+	return c.refreshZone(ctx, right)
+}
+
+// ChannelMaps returns the current channel assignments of the device's bonded zones.
+// stereo is set for a stereo pair, and homeTheater is set for a soundbar with
+// a Sub or surrounds; either may be nil.
+func (d *Device) ChannelMaps(ctx context.Context) (stereo, homeTheater ChannelMap, err error) {
+	m, _, err := d.topologyMember(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseChannelMap(m.ChannelMapSet), parseChannelMap(m.HTSatChanMapSet), nil
+}
+
+func (c *Client) addHTSatellites(ctx context.Context, soundbar *Device, chanMap string, sats ...*Device) error {
+	err := soundbar.soap(ctx, DevicePropertiesService, "AddHTSatellite", struct {
+		HTSatChanMapSet string
+	}{HTSatChanMapSet: soundbar.uid() + ":LF,RF;" + chanMap}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("adding home theater satellite: %w", err)
+	}
+	for _, sat := range sats {
+		if err := c.refreshZone(ctx, sat); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddSub bonds a Sub to a soundbar.
+func (c *Client) AddSub(ctx context.Context, soundbar, sub *Device) error {
+	return c.addHTSatellites(ctx, soundbar, sub.uid()+":SW", sub)
+}
+
+// AddSurrounds bonds a pair of surround speakers to a soundbar.
+func (c *Client) AddSurrounds(ctx context.Context, soundbar, left, right *Device) error {
+	return c.addHTSatellites(ctx, soundbar, left.uid()+":LR;"+right.uid()+":RR", left, right)
+}
+
+// RemoveSatellite unbonds a Sub or surround speaker from a soundbar.
+func (c *Client) RemoveSatellite(ctx context.Context, soundbar, sat *Device) error {
+	err := soundbar.soap(ctx, DevicePropertiesService, "RemoveHTSatellite", struct {
+		SatRoomUUID string
+	}{SatRoomUUID: sat.uid()}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("removing home theater satellite: %w", err)
+	}
+	return c.refreshZone(ctx, sat)
+}
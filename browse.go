@@ -0,0 +1,78 @@
+package sonos
+
+import (
+	"context"
+	"iter"
+)
+
+// BrowseItem is an object in a ContentDirectory, such as a track or an album.
+type BrowseItem struct {
+	ID        string // e.g. "A:ALBUM/Blue"
+	ParentID  string
+	Container bool   // whether the object has children of its own
+	Class     string // UPnP class, e.g. "object.item.audioItem.musicTrack"
+
+	Title   string
+	Creator string // usually the artist
+	Album   string
+	URI     string
+
+	AlbumArtURI string // often relative; see Device.AlbumArtURL
+
+	// Metadata is the object's DIDL-Lite XML, which is needed to add some objects to a queue.
+	Metadata string
+}
+
+// BrowseIter returns an iterator over the direct children of a ContentDirectory object
+// (e.g. "A:ALBUMARTIST", "SQ:" or "Q:0"), fetching them a page at a time as needed.
+// Within each page, containers are yielded before items.
+// If fetching a page fails, the error is yielded and iteration stops.
+func (d *Device) BrowseIter(ctx context.Context, objectID string) iter.Seq2[BrowseItem, error] {
+	return func(yield func(BrowseItem, error) bool) {
+		start := 0
+		for {
+			result, n, total, err := d.browse(ctx, objectID, start)
+			if err != nil {
+				yield(BrowseItem{}, err)
+				return
+			}
+			didl, err := parseDIDL(result)
+			if err != nil {
+				yield(BrowseItem{}, err)
+				return
+			}
+			for i := range didl.Containers {
+				if !yield(browseItem(&didl.Containers[i], true), nil) {
+					return
+				}
+			}
+			for i := range didl.Items {
+				if !yield(browseItem(&didl.Items[i], false), nil) {
+					return
+				}
+			}
+			start += n
+			if n == 0 || start >= total {
+				return
+			}
+		}
+	}
+}
+
+func browseItem(o *didlObject, container bool) BrowseItem {
+	return BrowseItem{
+		ID:        o.ID,
+		ParentID:  o.ParentID,
+		Container: container,
+		Class:     o.Class,
+
+		Title:   o.Title,
+		Creator: o.Creator,
+		Album:   o.Album,
+		URI:     o.uri(),
+
+		AlbumArtURI: o.AlbumArt,
+
+		Metadata: o.metadata(),
+	}
+}
@@ -0,0 +1,94 @@
+package sonos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+)
+
+// A Cache stores the results of discovery between program runs.
+type Cache interface {
+	// Load returns the cached devices. It returns an empty slice if there are none.
+	Load() ([]CachedDevice, error)
+	// Save replaces the cached devices.
+	Save([]CachedDevice) error
+}
+
+// CachedDevice is a device found by discovery.
+type CachedDevice struct {
+	Location string // URL of the device description
+	Zone     string `json:",omitempty"` // as of when the cache was saved; devices are asked for their current zone
+}
+
+// FileCache is a Cache that stores its data as JSON in the named file.
+type FileCache string
+
+func (fc FileCache) Load() ([]CachedDevice, error) {
+	data, err := os.ReadFile(string(fc))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var devs []CachedDevice
+	if err := json.Unmarshal(data, &devs); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", fc, err)
+	}
+	return devs, nil
+}
+
+func (fc FileCache) Save(devs []CachedDevice) error {
+	data, err := json.MarshalIndent(devs, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(string(fc), append(data, '\n'), 0644)
+}
+
+// loadCache populates the client from its cache.
+// It reports whether every cached device was successfully added.
+func (c *Client) loadCache(ctx context.Context) (bool, error) {
+	devs, err := c.opts.cache.Load()
+	if err != nil {
+		return false, err
+	}
+	if len(devs) == 0 {
+		return false, nil
+	}
+	var (
+		locs  []*url.URL
+		zones []string
+	)
+	for _, cd := range devs {
+		loc, err := url.Parse(cd.Location)
+		if err != nil {
+			return false, fmt.Errorf("parsing cached location %q: %w", cd.Location, err)
+		}
+		locs = append(locs, loc)
+		zones = append(zones, cd.Zone)
+	}
+	if errs := c.addDevices(ctx, locs, zones); len(errs) > 0 || ctx.Err() != nil {
+		return false, nil // stale; rediscover
+	}
+	// Zones may have been renamed since the cache was saved.
+	if err := c.opts.cache.Save(c.cacheEntries()); err != nil {
+		c.logger.WarnContext(ctx, "Saving discovery cache", "err", err)
+	}
+	return true, nil
+}
+
+// cacheEntries returns the client's devices in a form suitable for a Cache.
+func (c *Client) cacheEntries() []CachedDevice {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var devs []CachedDevice
+	for _, dev := range c.devices {
+		devs = append(devs, CachedDevice{
+			Location: c.meta[dev.UDN].loc.String(),
+			Zone:     c.zoneOf(dev.UDN),
+		})
+	}
+	return devs
+}
@@ -0,0 +1,43 @@
+package sonos
+
+import "strings"
+
+// Capabilities describes what a device supports.
+// It is derived from the device's model and the services it advertises,
+// so is a best guess for models newer than this package.
+type Capabilities struct {
+	LineIn      bool // analogue line-in
+	HomeTheater bool // TV input, night mode and speech enhancement
+	Battery     bool
+	Voice       bool // microphones for voice assistants
+	AudioClip   bool // playing notification clips over the current audio
+	FixedOutput bool // line-out that can be set to a fixed level
+}
+
+// Models with particular capabilities, by model name without any "Sonos " prefix.
+var (
+	batteryModels = map[string]bool{"Move": true, "Move 2": true, "Roam": true, "Roam SL": true, "Roam 2": true}
+	voiceModels   = map[string]bool{
+		"One": true, "Beam": true, "Arc": true, "Arc Ultra": true, "Move": true, "Move 2": true,
+		"Roam": true, "Roam 2": true, "Era 100": true, "Era 300": true,
+	}
+	lineOutModels = map[string]bool{"Port": true, "Connect": true, "ZP90": true}
+)
+
+// Capabilities reports what the device supports.
+func (d *Device) Capabilities() Capabilities {
+	model := strings.TrimPrefix(d.ModelName(), "Sonos ")
+	has := func(svc string) bool { return len(d.dev.FindService(svc)) > 0 }
+	caps := Capabilities{
+		HomeTheater: has(HTControlService),
+		Battery:     batteryModels[model],
+		Voice:       voiceModels[model],
+		FixedOutput: lineOutModels[model],
+	}
+	// Home theater devices have an AudioIn service for their TV input.
+	caps.LineIn = has(AudioInService) && !caps.HomeTheater
+	// Audio clips came with S2. Not all S2 devices support them;
+	// assume those with voice support do.
+	caps.AudioClip = d.Generation() == S2 && caps.Voice
+	return caps
+}
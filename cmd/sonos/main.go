@@ -0,0 +1,237 @@
+// The sonos command controls a Sonos system from the command line.
+//
+// Usage:
+//
+//	sonos [-timeout D] <command> [-zone Z] [args...]
+//
+// The commands are:
+//
+//	zones                  list the zones
+//	play                   start playback
+//	stop                   stop playback
+//	volume N               set the volume, in range [0,100]
+//	sleep D                set the sleep timer (e.g. "30m"), or "0" to clear it
+//	mode M                 set the play mode (normal, repeat-all, repeat-one, shuffle, shuffle-repeat, shuffle-repeat-one)
+//	queue list             list the queue
+//	queue clear            clear the queue
+//	queue dedupe           remove duplicate tracks from the queue
+//	queue shuffle          reorder the queue randomly
+//	playlist list NAME     list the tracks of a Sonos playlist
+//	playlist load NAME     add a Sonos playlist to the queue
+//	playlist play NAME     replace the queue with a Sonos playlist and play it
+//	linein [SOURCE]        play the line-in of the named zone, or the zone's own
+//	tv                     switch to TV input
+//
+// The zone (-zone) defaults to $SONOS_ZONE, or the only zone if there is just one.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dsymonds/sonos"
+)
+
+var timeout = flag.Duration("timeout", 10*time.Second, "overall timeout")
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: sonos [-timeout D] <command> [-zone Z] [args...]\n")
+	fmt.Fprintf(os.Stderr, "commands: zones, play, stop, volume, sleep, mode, queue, playlist, linein, tv\n")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("sonos: ")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+	}
+	cmd, args := flag.Arg(0), flag.Args()[1:]
+
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	zone := fs.String("zone", os.Getenv("SONOS_ZONE"), "zone to control")
+	fs.Parse(args)
+	args = fs.Args()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	sc, err := sonos.Discover(ctx)
+	if err != nil {
+		log.Fatalf("Discovering: %v", err)
+	}
+
+	if cmd == "zones" {
+		if err := listZones(ctx, sc); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	dev, err := zoneDevice(ctx, sc, *zone)
+	if err != nil {
+		log.Fatal(err)
+	}
+	switch cmd {
+	case "play":
+		err = dev.Play(ctx)
+	case "stop":
+		err = dev.Stop(ctx)
+	case "volume":
+		needArgs(cmd, args, 1)
+		var vol int
+		vol, err = strconv.Atoi(args[0])
+		if err != nil {
+			log.Fatalf("Bad volume %q: %v", args[0], err)
+		}
+		err = dev.SetVolume(ctx, vol)
+	case "sleep":
+		needArgs(cmd, args, 1)
+		var d time.Duration
+		if args[0] != "0" {
+			d, err = time.ParseDuration(args[0])
+			if err != nil {
+				log.Fatalf("Bad duration %q: %v", args[0], err)
+			}
+		}
+		err = dev.SetSleepTimer(ctx, d)
+	case "mode":
+		needArgs(cmd, args, 1)
+		mode, ok := playModes[args[0]]
+		if !ok {
+			log.Fatalf("Unknown play mode %q", args[0])
+		}
+		err = dev.SetPlayMode(ctx, mode)
+	case "queue":
+		needArgs(cmd, args, 1)
+		switch args[0] {
+		case "list":
+			err = listQueue(ctx, dev)
+		case "clear":
+			err = dev.ClearQueue(ctx)
+		case "dedupe":
+			var n int
+			n, err = dev.DedupeQueue(ctx)
+			if err == nil {
+				fmt.Printf("Removed %d duplicate tracks.\n", n)
+			}
+		case "shuffle":
+			err = dev.ShuffleQueue(ctx)
+		default:
+			log.Fatalf("Unknown queue command %q", args[0])
+		}
+	case "playlist":
+		needArgs(cmd, args, 2)
+		switch args[0] {
+		case "list":
+			err = listPlaylist(ctx, dev, args[1])
+		case "load":
+			var res sonos.EnqueueResult
+			res, err = dev.LoadSonosPlaylist(ctx, args[1], sonos.MatchExact, sonos.EnqueueOptions{})
+			if err == nil {
+				fmt.Printf("Added %d tracks; queue now has %d.\n", res.TracksAdded, res.QueueLength)
+			}
+		case "play":
+			err = dev.PlaySonosPlaylist(ctx, args[1], sonos.PlayOptions{})
+		default:
+			log.Fatalf("Unknown playlist command %q", args[0])
+		}
+	case "linein":
+		src := dev
+		if len(args) > 0 {
+			src, err = sc.ZoneDevice(ctx, args[0])
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		err = dev.PlayLineIn(ctx, src)
+	case "tv":
+		err = dev.PlayTV(ctx)
+	default:
+		usage()
+	}
+	if err != nil {
+		if msg := sonos.Explain(err); msg != "" {
+			log.Fatalf("%v\n%s", err, msg)
+		}
+		log.Fatal(err)
+	}
+}
+
+var playModes = map[string]sonos.PlayMode{
+	"normal":             sonos.NormalPlayMode,
+	"repeat-all":         sonos.RepeatAll,
+	"repeat-one":         sonos.RepeatOne,
+	"shuffle":            sonos.Shuffle,
+	"shuffle-repeat":     sonos.ShuffleRepeat,
+	"shuffle-repeat-one": sonos.ShuffleRepeatOne,
+}
+
+func needArgs(cmd string, args []string, n int) {
+	if len(args) < n {
+		log.Fatalf("%s needs %d argument(s)", cmd, n)
+	}
+}
+
+func zoneDevice(ctx context.Context, sc *sonos.Client, zone string) (*sonos.Device, error) {
+	if zone == "" {
+		zones, err := sc.Zones(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(zones) != 1 {
+			return nil, fmt.Errorf("found %d zones; pick one with -zone or $SONOS_ZONE", len(zones))
+		}
+		zone = zones[0].Name
+	}
+	return sc.ZoneDevice(ctx, zone)
+}
+
+func listZones(ctx context.Context, sc *sonos.Client) error {
+	zones, err := sc.Zones(ctx)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "ZONE\tDEVICES\tCOORDINATOR\tSYSTEM\n")
+	for _, z := range zones {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", z.Name, z.NumDevices, z.Coordinator, z.Generation)
+	}
+	return tw.Flush()
+}
+
+func listQueue(ctx context.Context, dev *sonos.Device) error {
+	items, err := dev.Queue(ctx)
+	if err != nil {
+		return err
+	}
+	return printItems(items)
+}
+
+func listPlaylist(ctx context.Context, dev *sonos.Device, name string) error {
+	pl, err := dev.FindSonosPlaylist(ctx, name)
+	if err != nil {
+		return err
+	}
+	items, _, err := dev.SonosPlaylistTracks(ctx, pl.ID, 0, 0)
+	if err != nil {
+		return err
+	}
+	return printItems(items)
+}
+
+func printItems(items []sonos.QueueItem) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	for i, it := range items {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", i+1, it.Title, it.Creator, it.Album)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,196 @@
+package sonos
+
+import (
+	"context"
+	"fmt"
+)
+
+// LEDState reports whether the device's white status light is on.
+func (d *Device) LEDState(ctx context.Context) (on bool, err error) {
+	var resp struct {
+		CurrentLEDState string // "On" or "Off"
+	}
+	err = d.soap(ctx, DevicePropertiesService, "GetLEDState", struct{}{}, &resp)
+	if err != nil {
+		return false, fmt.Errorf("getting LED state: %w", err)
+	}
+	return resp.CurrentLEDState == "On", nil
+}
+
+// SetLEDState turns the device's white status light on or off.
+func (d *Device) SetLEDState(ctx context.Context, on bool) error {
+	err := d.soap(ctx, DevicePropertiesService, "SetLEDState", struct {
+		DesiredLEDState string
+	}{DesiredLEDState: onOff(on)}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting LED state: %w", err)
+	}
+	return nil
+}
+
+// ButtonLock reports whether the device's physical controls are locked.
+func (d *Device) ButtonLock(ctx context.Context) (locked bool, err error) {
+	var resp struct {
+		CurrentButtonLockState string // "On" or "Off"
+	}
+	err = d.soap(ctx, DevicePropertiesService, "GetButtonLockState", struct{}{}, &resp)
+	if err != nil {
+		return false, fmt.Errorf("getting button lock state: %w", err)
+	}
+	return resp.CurrentButtonLockState == "On", nil
+}
+
+// SetButtonLock locks or unlocks the device's physical controls.
+func (d *Device) SetButtonLock(ctx context.Context, locked bool) error {
+	err := d.soap(ctx, DevicePropertiesService, "SetButtonLockState", struct {
+		DesiredButtonLockState string
+	}{DesiredButtonLockState: onOff(locked)}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting button lock state: %w", err)
+	}
+	return nil
+}
+
+// ZoneAttributes describes the room that a device belongs to.
+type ZoneAttributes struct {
+	Name          string
+	Icon          string // e.g. "x-rincon-roomicon:living"
+	Configuration string // opaque flags set by the Sonos app, e.g. "1"
+
+	// TargetRoomName is the room the device is being moved to, if any.
+	// It is only reported, and is ignored by SetZoneAttributes.
+	TargetRoomName string
+}
+
+// ZoneAttributes returns the attributes of the room that the device belongs to.
+func (d *Device) ZoneAttributes(ctx context.Context) (ZoneAttributes, error) {
+	var resp struct {
+		CurrentZoneName       string
+		CurrentIcon           string
+		CurrentConfiguration  string
+		CurrentTargetRoomName string // absent on older firmware
+	}
+	err := d.soap(ctx, DevicePropertiesService, "GetZoneAttributes", struct{}{}, &resp)
+	if err != nil {
+		return ZoneAttributes{}, fmt.Errorf("getting zone attributes: %w", err)
+	}
+	return ZoneAttributes{
+		Name:           resp.CurrentZoneName,
+		Icon:           resp.CurrentIcon,
+		Configuration:  resp.CurrentConfiguration,
+		TargetRoomName: resp.CurrentTargetRoomName,
+	}, nil
+}
+
+// SetZoneName renames the room that the device belongs to.
+func (d *Device) SetZoneName(ctx context.Context, name string) error {
+	return d.SetZoneAttributes(ctx, ZoneAttributes{Name: name})
+}
+
+// SetZoneAttributes changes the attributes of the room that the device belongs to.
+// Any empty fields are left unchanged.
+// If the device came from a Client, the Client's zones are updated to match.
+func (d *Device) SetZoneAttributes(ctx context.Context, attrs ZoneAttributes) error {
+	cur, err := d.ZoneAttributes(ctx)
+	if err != nil {
+		return err
+	}
+	if attrs.Name == "" {
+		attrs.Name = cur.Name
+	}
+	if attrs.Icon == "" {
+		attrs.Icon = cur.Icon
+	}
+	if attrs.Configuration == "" {
+		attrs.Configuration = cur.Configuration
+	}
+	err = d.soap(ctx, DevicePropertiesService, "SetZoneAttributes", struct {
+		DesiredZoneName      string
+		DesiredIcon          string
+		DesiredConfiguration string
+	}{
+		DesiredZoneName:      attrs.Name,
+		DesiredIcon:          attrs.Icon,
+		DesiredConfiguration: attrs.Configuration,
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting zone attributes: %w", err)
+	}
+	if d.c != nil {
+		d.c.renameZone(cur.Name, attrs.Name)
+		d.c.setIcon(d.dev.UDN, attrs.Icon)
+	}
+	return nil
+}
+
+// Info is the hardware and software information for a device.
+type Info struct {
+	SerialNumber           string
+	SoftwareVersion        string
+	DisplaySoftwareVersion string // e.g. "15.9"
+	HardwareVersion        string
+	IPAddress              string
+	MACAddress             string
+}
+
+// Info returns the hardware and software information for the device.
+func (d *Device) Info(ctx context.Context) (Info, error) {
+	var resp struct {
+		SerialNumber           string
+		SoftwareVersion        string
+		DisplaySoftwareVersion string
+		HardwareVersion        string
+		IPAddress              string
+		MACAddress             string
+		CopyrightInfo          string
+		ExtraInfo              string
+		HTAudioIn              string
+		Flags                  string
+	}
+	err := d.soap(ctx, DevicePropertiesService, "GetZoneInfo", struct{}{}, &resp)
+	if err != nil {
+		return Info{}, fmt.Errorf("getting zone info: %w", err)
+	}
+	return Info{
+		SerialNumber:           resp.SerialNumber,
+		SoftwareVersion:        resp.SoftwareVersion,
+		DisplaySoftwareVersion: resp.DisplaySoftwareVersion,
+		HardwareVersion:        resp.HardwareVersion,
+		IPAddress:              resp.IPAddress,
+		MACAddress:             resp.MACAddress,
+	}, nil
+}
+
+// HouseholdID returns the identifier of the Sonos system that the device belongs to
+// (e.g. "Sonos_abcdefghijklmnopqrstuvwxyz").
+func (d *Device) HouseholdID(ctx context.Context) (string, error) {
+	var resp struct {
+		CurrentHouseholdID string
+	}
+	err := d.soap(ctx, DevicePropertiesService, "GetHouseholdID", struct{}{}, &resp)
+	if err != nil {
+		return "", fmt.Errorf("getting household ID: %w", err)
+	}
+	return resp.CurrentHouseholdID, nil
+}
+
+// onOff returns the DeviceProperties encoding of a switch state.
+func onOff(on bool) string {
+	if on {
+		return "On"
+	}
+	return "Off"
+}
+
+// MicEnabled reports whether the microphones of a voice-capable device are switched on.
+// Devices without microphones (see Capabilities) report an error.
+func (d *Device) MicEnabled(ctx context.Context) (bool, error) {
+	var resp struct {
+		MicEnabled string // bool
+	}
+	err := d.soap(ctx, DevicePropertiesService, "GetMicEnabled", struct{}{}, &resp)
+	if err != nil {
+		return false, fmt.Errorf("getting microphone state: %w", err)
+	}
+	return resp.MicEnabled == "1", nil
+}
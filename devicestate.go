@@ -0,0 +1,92 @@
+package sonos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DeviceState is what a device is doing, gathered in one go by Device.State
+// for reporting elsewhere, such as to a home automation system.
+// In JSON, the play mode is its Sonos name (e.g. "REPEAT_ALL"),
+// and durations are in whole seconds.
+type DeviceState struct {
+	Zone       string
+	State      TransportState
+	Track      PositionInfo // the current track, and how far through it is
+	Volume     int
+	Mute       bool
+	Mode       PlayMode
+	Group      GroupState
+	SleepTimer time.Duration // time remaining, or zero if not set
+}
+
+// MarshalJSON encodes the state as described for DeviceState.
+func (st DeviceState) MarshalJSON() ([]byte, error) {
+	// The outer fields hide the embedded ones of the same name.
+	type track struct {
+		PositionInfo
+		Duration int
+		Position int
+	}
+	type state DeviceState // without this method
+	return json.Marshal(struct {
+		state
+		Track      track
+		SleepTimer int
+	}{
+		state: state(st),
+		Track: track{
+			PositionInfo: st.Track,
+			Duration:     int(st.Track.Duration / time.Second),
+			Position:     int(st.Track.Position / time.Second),
+		},
+		SleepTimer: int(st.SleepTimer / time.Second),
+	})
+}
+
+// GroupState is the group a device belongs to, within a DeviceState.
+type GroupState struct {
+	ID          string
+	Coordinator string   // UUID of the coordinating member
+	Members     []string // zone names of the visible members, including the device
+}
+
+// State returns the device's transport state, current track and position, volume,
+// play mode, group and sleep timer. The underlying requests are made concurrently.
+func (d *Device) State(ctx context.Context) (*DeviceState, error) {
+	st := &DeviceState{Zone: d.RoomName()}
+	var group ZoneGroup
+	fetches := []func() error{
+		func() (err error) { st.State, err = d.TransportState(ctx); return },
+		func() (err error) { st.Track, err = d.PositionInfo(ctx); return },
+		func() (err error) { st.Volume, err = d.Volume(ctx); return },
+		func() (err error) { st.Mute, err = d.Mute(ctx); return },
+		func() (err error) { st.Mode, err = d.PlayMode(ctx); return },
+		func() (err error) { _, group, err = d.topologyMember(ctx); return },
+		func() (err error) { st.SleepTimer, err = d.SleepTimer(ctx); return },
+	}
+	errs := make([]error, len(fetches))
+	var wg sync.WaitGroup
+	for i, f := range fetches {
+		wg.Add(1)
+		go func(i int, f func() error) {
+			defer wg.Done()
+			errs[i] = f()
+		}(i, f)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	st.Group = GroupState{ID: group.ID, Coordinator: group.Coordinator}
+	for _, m := range group.Members {
+		if !m.Invisible {
+			st.Group.Members = append(st.Group.Members, m.ZoneName)
+		}
+	}
+	return st, nil
+}
@@ -0,0 +1,204 @@
+package sonos
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrDiagnosticsDisabled is returned by Device.Diagnostics unless the Client
+// was created with WithDiagnostics.
+var ErrDiagnosticsDisabled = errors.New("diagnostics not enabled")
+
+// Diagnostics is a snapshot of a device's health, gathered from its status pages.
+// Pages that the device does not serve leave their fields zero.
+//
+// There are no audio input rates: no status page reports the format of
+// line-in or TV audio, which devices only announce through the HTAudioIn
+// state variable of DeviceProperties events, as an undocumented code.
+type Diagnostics struct {
+	Uptime      time.Duration
+	LoadAverage [3]float64 // over 1, 5 and 15 minutes
+	MemTotal    int64      // bytes
+	MemFree     int64      // bytes
+	Interfaces  []InterfaceStats
+}
+
+// InterfaceStats are the traffic counts of a device's network interface.
+type InterfaceStats struct {
+	Name               string // e.g. "eth0", "ath0"
+	RXBytes, TXBytes   int64
+	RXErrors, TXErrors int64
+}
+
+// Diagnostics gathers a snapshot of the device's health.
+// The Client must have been created with WithDiagnostics.
+func (d *Device) Diagnostics(ctx context.Context) (*Diagnostics, error) {
+	if d.c == nil || !d.c.opts.diagnostics {
+		return nil, ErrDiagnosticsDisabled
+	}
+	diag := new(Diagnostics)
+
+	page := func(path string) (string, error) {
+		s, err := d.statusFile(ctx, path)
+		if err == errNotFound {
+			return "", nil
+		} else if err != nil {
+			return "", fmt.Errorf("getting %s: %w", path, err)
+		}
+		return s, nil
+	}
+
+	uptime, err := page("/status/proc/uptime")
+	if err != nil {
+		return nil, err
+	}
+	if f := strings.Fields(uptime); len(f) > 0 {
+		if secs, err := strconv.ParseFloat(f[0], 64); err == nil {
+			diag.Uptime = time.Duration(secs * float64(time.Second)).Round(time.Second)
+		}
+	}
+
+	load, err := page("/status/proc/loadavg")
+	if err != nil {
+		return nil, err
+	}
+	for i, f := range strings.Fields(load) {
+		if i >= len(diag.LoadAverage) {
+			break
+		}
+		diag.LoadAverage[i], _ = strconv.ParseFloat(f, 64)
+	}
+
+	mem, err := page("/status/proc/meminfo")
+	if err != nil {
+		return nil, err
+	}
+	sc := bufio.NewScanner(strings.NewReader(mem))
+	for sc.Scan() {
+		// Lines look like "MemFree:    12345 kB".
+		f := strings.Fields(sc.Text())
+		if len(f) < 2 {
+			continue
+		}
+		n, err := strconv.ParseInt(f[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch f[0] {
+		case "MemTotal:":
+			diag.MemTotal = n << 10
+		case "MemFree:":
+			diag.MemFree = n << 10
+		}
+	}
+
+	ifconfig, err := page("/status/ifconfig")
+	if err != nil {
+		return nil, err
+	}
+	diag.Interfaces = parseIfconfig(ifconfig)
+	return diag, nil
+}
+
+var (
+	ifconfigCountsRE = regexp.MustCompile(`([RT]X) packets:\d+ errors:(\d+)`)
+	ifconfigBytesRE  = regexp.MustCompile(`RX bytes:(\d+).*TX bytes:(\d+)`)
+)
+
+// parseIfconfig parses the output of BusyBox's ifconfig.
+func parseIfconfig(s string) []InterfaceStats {
+	var ifaces []InterfaceStats
+	for _, line := range strings.Split(s, "\n") {
+		if line == "" {
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			// A new interface.
+			ifaces = append(ifaces, InterfaceStats{Name: strings.Fields(line)[0]})
+			continue
+		}
+		if len(ifaces) == 0 {
+			continue
+		}
+		is := &ifaces[len(ifaces)-1]
+		if m := ifconfigCountsRE.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.ParseInt(m[2], 10, 64)
+			if m[1] == "RX" {
+				is.RXErrors = n
+			} else {
+				is.TXErrors = n
+			}
+		}
+		if m := ifconfigBytesRE.FindStringSubmatch(line); m != nil {
+			is.RXBytes, _ = strconv.ParseInt(m[1], 10, 64)
+			is.TXBytes, _ = strconv.ParseInt(m[2], 10, 64)
+		}
+	}
+	return ifaces
+}
+
+// SignalMatrix holds the wireless signal strengths (RSSI) between devices.
+// matrix[a][b] is how strongly device a hears device b.
+// Devices are identified by UUID, or by MAC address if no known device has it.
+type SignalMatrix map[string]map[string]int
+
+var macRE = regexp.MustCompile(`(?i)\b[0-9a-f]{2}(?::[0-9a-f]{2}){5}\b`)
+
+// SignalMatrix gathers how strongly every wireless device hears its neighbours,
+// from the devices' status pages. Wired devices and those whose firmware
+// does not report neighbours are omitted.
+// The Client must have been created with WithDiagnostics.
+func (c *Client) SignalMatrix(ctx context.Context) (SignalMatrix, error) {
+	if !c.opts.diagnostics {
+		return nil, ErrDiagnosticsDisabled
+	}
+	devs := c.Devices()
+	// Device UUIDs embed their MAC addresses: RINCON_<MAC><port>.
+	byMAC := make(map[string]string)
+	for _, d := range devs {
+		if id := strings.TrimPrefix(d.UUID(), "RINCON_"); len(id) >= 12 {
+			byMAC[strings.ToUpper(id[:12])] = d.UUID()
+		}
+	}
+
+	matrix := make(SignalMatrix)
+	var errs []error
+	for _, d := range devs {
+		status, err := d.statusFile(ctx, "/status/proc/ath_rincon/status")
+		if err == errNotFound {
+			continue
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("%s: getting wireless status: %w", d.UUID(), err))
+			continue
+		}
+		for _, line := range strings.Split(status, "\n") {
+			mac := macRE.FindString(line)
+			rssi := rssiRE.FindStringSubmatch(line)
+			if mac == "" || rssi == nil {
+				continue
+			}
+			n, err := strconv.Atoi(rssi[1])
+			if err != nil {
+				continue
+			}
+			peer := mac
+			if id, ok := byMAC[strings.ToUpper(strings.ReplaceAll(mac, ":", ""))]; ok {
+				peer = id
+			}
+			if peer == d.UUID() {
+				continue
+			}
+			if matrix[d.UUID()] == nil {
+				matrix[d.UUID()] = make(map[string]int)
+			}
+			matrix[d.UUID()][peer] = n
+		}
+	}
+	return matrix, errors.Join(errs...)
+}
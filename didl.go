@@ -0,0 +1,250 @@
+package sonos
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// XML namespaces used in DIDL-Lite metadata.
+const (
+	nsDIDL = "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/"
+	nsDC   = "http://purl.org/dc/elements/1.1/"
+	nsUPnP = "urn:schemas-upnp-org:metadata-1-0/upnp/"
+	nsR    = "urn:schemas-rinconnetworks-com:metadata-1-0/"
+)
+
+// didlLite is a DIDL-Lite document, as returned by browsing
+// and in track and URI metadata.
+type didlLite struct {
+	XMLName    xml.Name     `xml:"urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/ DIDL-Lite"`
+	Items      []didlObject `xml:"urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/ item"`
+	Containers []didlObject `xml:"urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/ container"`
+}
+
+// didlObject is an item or container in a DIDL-Lite document.
+// Elements not listed here are ignored.
+type didlObject struct {
+	ID         string    `xml:"id,attr"`
+	ParentID   string    `xml:"parentID,attr"`
+	Restricted string    `xml:"restricted,attr"`
+	Title      string    `xml:"http://purl.org/dc/elements/1.1/ title"`
+	Creator    string    `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Album      string    `xml:"urn:schemas-upnp-org:metadata-1-0/upnp/ album"`
+	Class      string    `xml:"urn:schemas-upnp-org:metadata-1-0/upnp/ class"`
+	AlbumArt   string    `xml:"urn:schemas-upnp-org:metadata-1-0/upnp/ albumArtURI"`
+	Res        []didlRes `xml:"urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/ res"`
+	Desc       string    `xml:"urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/ desc"`
+
+	StreamContent string `xml:"urn:schemas-rinconnetworks-com:metadata-1-0/ streamContent"`
+	ResMD         string `xml:"urn:schemas-rinconnetworks-com:metadata-1-0/ resMD"` // for favorites
+
+	inner string // raw XML content
+	item  bool   // whether this is an item, not a container
+}
+
+// UnmarshalXML records the raw content of the object as well as decoding it.
+func (o *didlObject) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	// Collect the tokens of the content, then decode those.
+	var toks tokenSlice
+	for depth := 0; ; {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+		if depth < 0 {
+			break
+		}
+		toks = append(toks, xml.CopyToken(tok))
+	}
+	o.inner = toks.String()
+	o.item = start.Name.Local == "item"
+
+	type plain didlObject // without this method
+	toks = append(append(tokenSlice{start}, toks...), start.End())
+	return xml.NewTokenDecoder(&toks).Decode((*plain)(o))
+}
+
+// tokenSlice is an xml.TokenReader of a fixed sequence of tokens.
+type tokenSlice []xml.Token
+
+func (ts *tokenSlice) Token() (xml.Token, error) {
+	if len(*ts) == 0 {
+		return nil, io.EOF
+	}
+	tok := (*ts)[0]
+	*ts = (*ts)[1:]
+	return tok, nil
+}
+
+// String serializes the tokens as XML, using the conventional DIDL-Lite prefixes
+// as declared by didlHeader.
+func (ts tokenSlice) String() string {
+	var buf strings.Builder
+	name := func(n xml.Name) string {
+		if p, ok := didlNamespaces[n.Space]; ok && p != "" {
+			return p + ":" + n.Local
+		}
+		return n.Local
+	}
+	for _, tok := range ts {
+		switch t := tok.(type) {
+		case xml.StartElement:
+			buf.WriteString("<" + name(t.Name))
+			if _, ok := didlNamespaces[t.Name.Space]; !ok && t.Name.Space != "" {
+				buf.WriteString(` xmlns="`)
+				xml.EscapeText(&buf, []byte(t.Name.Space))
+				buf.WriteString(`"`)
+			}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" && attr.Name.Space == "" {
+					continue
+				}
+				buf.WriteString(" " + name(attr.Name) + `="`)
+				xml.EscapeText(&buf, []byte(attr.Value))
+				buf.WriteString(`"`)
+			}
+			buf.WriteString(">")
+		case xml.EndElement:
+			buf.WriteString("</" + name(t.Name) + ">")
+		case xml.CharData:
+			xml.EscapeText(&buf, t)
+		}
+	}
+	return buf.String()
+}
+
+// didlRes is a resource (a playable URI) of a DIDL-Lite object.
+type didlRes struct {
+	ProtocolInfo string `xml:"protocolInfo,attr"`
+	Duration     string `xml:"duration,attr"`
+	URI          string `xml:",chardata"`
+}
+
+// uri returns the URI of the object's first resource, or "" if it has none.
+func (o *didlObject) uri() string {
+	if len(o.Res) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(o.Res[0].URI)
+}
+
+// didlHeader opens a DIDL-Lite document, declaring the conventional prefixes.
+const didlHeader = `<DIDL-Lite xmlns:dc="` + nsDC + `" xmlns:upnp="` + nsUPnP + `" xmlns:r="` + nsR + `" xmlns="` + nsDIDL + `">`
+
+// metadata returns a DIDL-Lite document holding just this object,
+// suitable for passing back to a device.
+func (o *didlObject) metadata() string {
+	if o.inner == "" {
+		return ""
+	}
+	elem := "container"
+	if o.item {
+		elem = "item"
+	}
+	var buf strings.Builder
+	buf.WriteString(didlHeader)
+	fmt.Fprintf(&buf, "<%s", elem)
+	for _, attr := range [][2]string{{"id", o.ID}, {"parentID", o.ParentID}, {"restricted", o.Restricted}} {
+		if attr[1] != "" {
+			fmt.Fprintf(&buf, ` %s="`, attr[0])
+			xml.EscapeText(&buf, []byte(attr[1]))
+			buf.WriteString(`"`)
+		}
+	}
+	fmt.Fprintf(&buf, ">%s</%s></DIDL-Lite>", o.inner, elem)
+	return buf.String()
+}
+
+// parseDIDL parses DIDL-Lite XML.
+// An empty string, or "NOT_IMPLEMENTED" as some devices report, yields an empty document.
+//
+// Parsing is lenient: unknown elements are skipped, HTML entities are accepted,
+// and the usual prefixes (dc, upnp, r) are understood even when the
+// metadata fails to declare them, as some music services do.
+func parseDIDL(s string) (*didlLite, error) {
+	didl := new(didlLite)
+	if s == "" || s == "NOT_IMPLEMENTED" {
+		return didl, nil
+	}
+	raw := xml.NewDecoder(strings.NewReader(s))
+	raw.Strict = false
+	raw.Entity = xml.HTMLEntity
+	dec := xml.NewTokenDecoder(didlTokenReader{raw})
+	if err := dec.Decode(didl); err != nil {
+		return nil, fmt.Errorf("parsing DIDL-Lite XML: %w", err)
+	}
+	return didl, nil
+}
+
+// didlPrefixes maps the conventional DIDL-Lite prefixes to their namespaces.
+var didlPrefixes = map[string]string{
+	"":     nsDIDL,
+	"dc":   nsDC,
+	"upnp": nsUPnP,
+	"r":    nsR,
+}
+
+// didlNamespaces is the inverse of didlPrefixes.
+var didlNamespaces = map[string]string{
+	nsDIDL: "",
+	nsDC:   "dc",
+	nsUPnP: "upnp",
+	nsR:    "r",
+}
+
+// didlTokenReader fills in the namespaces of elements that lack them.
+type didlTokenReader struct {
+	dec *xml.Decoder
+}
+
+func (tr didlTokenReader) Token() (xml.Token, error) {
+	tok, err := tr.dec.Token()
+	switch t := tok.(type) {
+	case xml.StartElement:
+		t.Name = fixDIDLName(t.Name)
+		tok = t
+	case xml.EndElement:
+		t.Name = fixDIDLName(t.Name)
+		tok = t
+	}
+	return tok, err
+}
+
+// fixDIDLName returns the name with a namespace in place of any undeclared prefix.
+// The decoder leaves an undeclared prefix in Space, which is never a URI.
+func fixDIDLName(n xml.Name) xml.Name {
+	if ns, ok := didlPrefixes[n.Space]; ok {
+		n.Space = ns
+	}
+	return n
+}
+
+// trackMetadata returns a DIDL-Lite document describing a single playable URI.
+func trackMetadata(uri, mimeType, class, title, creator string) string {
+	var buf strings.Builder
+	buf.WriteString(didlHeader)
+	buf.WriteString(`<item id="-1" parentID="-1" restricted="true">`)
+	fmt.Fprintf(&buf, `<dc:title>%s</dc:title>`, escapeXML(title))
+	if creator != "" {
+		fmt.Fprintf(&buf, `<dc:creator>%s</dc:creator>`, escapeXML(creator))
+	}
+	fmt.Fprintf(&buf, `<upnp:class>%s</upnp:class>`, class)
+	fmt.Fprintf(&buf, `<res protocolInfo="http-get:*:%s:*">%s</res>`, escapeXML(mimeType), escapeXML(uri))
+	buf.WriteString(`</item></DIDL-Lite>`)
+	return buf.String()
+}
+
+// escapeXML escapes s for use in XML text or attribute values.
+func escapeXML(s string) string {
+	var buf strings.Builder
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
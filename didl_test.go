@@ -0,0 +1,124 @@
+package sonos
+
+import "testing"
+
+func TestParseDIDL(t *testing.T) {
+	tests := []struct {
+		desc       string
+		in         string
+		items      []QueueItem
+		containers []string // titles
+	}{
+		{desc: "empty", in: ""},
+		{desc: "not implemented", in: "NOT_IMPLEMENTED"},
+		{
+			desc: "track",
+			in: `<DIDL-Lite xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/" xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/">` +
+				`<item id="Q:0/1" parentID="Q:0" restricted="true"><dc:title>Blue in Green</dc:title><dc:creator>Miles Davis</dc:creator>` +
+				`<upnp:album>Kind of Blue</upnp:album><upnp:albumArtURI>/getaa?u=x</upnp:albumArtURI>` +
+				`<upnp:class>object.item.audioItem.musicTrack</upnp:class>` +
+				`<res protocolInfo="x-file-cifs:*:audio/flac:*" duration="0:05:37">
+					x-file-cifs://nas/music/03.flac
+				</res></item></DIDL-Lite>`,
+			items: []QueueItem{{
+				Title:       "Blue in Green",
+				Creator:     "Miles Davis",
+				Album:       "Kind of Blue",
+				URI:         "x-file-cifs://nas/music/03.flac",
+				AlbumArtURI: "/getaa?u=x",
+			}},
+		},
+		{
+			desc: "undeclared prefixes and HTML entities",
+			in: `<DIDL-Lite xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/">` +
+				`<item id="1"><dc:title>Rock&nbsp;&amp;&nbsp;Roll</dc:title><upnp:class>object.item</upnp:class>` +
+				`<res>x-sonos-http:track%3a1.mp4?sid=2</res></item></DIDL-Lite>`,
+			items: []QueueItem{{Title: "Rock & Roll", URI: "x-sonos-http:track%3a1.mp4?sid=2"}},
+		},
+		{
+			desc: "containers",
+			in: `<DIDL-Lite xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/">` +
+				`<container id="SQ:1"><dc:title>One</dc:title></container>` +
+				`<container id="SQ:2"><dc:title>Two</dc:title><unknown><nested/></unknown></container></DIDL-Lite>`,
+			containers: []string{"One", "Two"},
+		},
+	}
+	for _, tc := range tests {
+		didl, err := parseDIDL(tc.in)
+		if err != nil {
+			t.Errorf("%s: parseDIDL: %v", tc.desc, err)
+			continue
+		}
+		if len(didl.Items) != len(tc.items) {
+			t.Errorf("%s: got %d items, want %d", tc.desc, len(didl.Items), len(tc.items))
+			continue
+		}
+		for i, it := range didl.Items {
+			got := queueItem(it)
+			got.Metadata = ""
+			if got != tc.items[i] {
+				t.Errorf("%s: item %d is %+v, want %+v", tc.desc, i, got, tc.items[i])
+			}
+		}
+		var titles []string
+		for _, c := range didl.Containers {
+			titles = append(titles, c.Title)
+		}
+		if len(titles) != len(tc.containers) {
+			t.Errorf("%s: got containers %q, want %q", tc.desc, titles, tc.containers)
+			continue
+		}
+		for i := range titles {
+			if titles[i] != tc.containers[i] {
+				t.Errorf("%s: got containers %q, want %q", tc.desc, titles, tc.containers)
+				break
+			}
+		}
+	}
+}
+
+func TestParseDIDLInvalid(t *testing.T) {
+	if _, err := parseDIDL(`<foo>`); err == nil {
+		t.Errorf("parseDIDL of non-DIDL succeeded")
+	}
+}
+
+func TestDIDLMetadataRoundTrip(t *testing.T) {
+	// An object's metadata parses back to the same object.
+	in := `<DIDL-Lite xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/" xmlns:r="urn:schemas-rinconnetworks-com:metadata-1-0/" xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/">` +
+		`<item id="10032020track%3a1" parentID="-1" restricted="true"><dc:title>Fish &amp; Chips</dc:title>` +
+		`<upnp:class>object.item.audioItem.musicTrack</upnp:class>` +
+		`<desc id="cdudn" nameSpace="urn:schemas-rinconnetworks-com:metadata-1-0/">SA_RINCON2311_X_#Svc2311-0-Token</desc>` +
+		`<r:streamContent>live</r:streamContent></item></DIDL-Lite>`
+	didl, err := parseDIDL(in)
+	if err != nil {
+		t.Fatalf("parseDIDL: %v", err)
+	}
+	md := didl.Items[0].metadata()
+	again, err := parseDIDL(md)
+	if err != nil {
+		t.Fatalf("parseDIDL of metadata %q: %v", md, err)
+	}
+	if len(again.Items) != 1 {
+		t.Fatalf("metadata %q has %d items, want 1", md, len(again.Items))
+	}
+	orig, got := didl.Items[0], again.Items[0]
+	if got.ID != orig.ID || got.Title != orig.Title || got.Class != orig.Class || got.Desc != orig.Desc || got.StreamContent != orig.StreamContent {
+		t.Errorf("metadata %q parsed as %+v, want %+v", md, got, orig)
+	}
+}
+
+func TestTrackMetadata(t *testing.T) {
+	md := trackMetadata("http://example.com/a&b.mp3", "audio/mpeg", "object.item.audioItem.musicTrack", "A <B>", "C")
+	didl, err := parseDIDL(md)
+	if err != nil {
+		t.Fatalf("parseDIDL(%q): %v", md, err)
+	}
+	if len(didl.Items) != 1 {
+		t.Fatalf("trackMetadata has %d items, want 1", len(didl.Items))
+	}
+	it := queueItem(didl.Items[0])
+	if it.URI != "http://example.com/a&b.mp3" || it.Title != "A <B>" || it.Creator != "C" {
+		t.Errorf("trackMetadata parsed as %+v", it)
+	}
+}
@@ -0,0 +1,258 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/huin/goupnp/dcps/av1"
+)
+
+// EQPreset is a set of a device's equalisation settings, such as a "movie" or "music" tuning.
+// Settings that are nil are left unchanged by ApplyEQ,
+// and are not reported by CurrentEQ for devices that lack them.
+type EQPreset struct {
+	Bass     int // in range [-10,10]
+	Treble   int // in range [-10,10]
+	Loudness bool
+	Balance  int // in range [-100,100]; negative favours the left channel
+
+	NightMode     *bool // home theater devices only
+	SubLevel      *int  // in range [-15,15]; only with a bonded Sub
+	SurroundLevel *int  // in range [-15,15]; only with bonded surrounds
+	HeightLevel   *int  // in range [-10,10]; only for devices with height channels, e.g. Arc
+}
+
+// EQType identifies one of a device's extended EQ settings.
+// Which are available depends on the device and what is bonded to it.
+type EQType string
+
+const (
+	NightModeEQ          EQType = "NightMode"          // 0 or 1
+	DialogLevelEQ        EQType = "DialogLevel"        // speech enhancement; 0 or 1
+	SubEnableEQ          EQType = "SubEnable"          // 0 or 1
+	SubGainEQ            EQType = "SubGain"            // in range [-15,15]
+	SurroundEnableEQ     EQType = "SurroundEnable"     // 0 or 1
+	SurroundLevelEQ      EQType = "SurroundLevel"      // for TV, in range [-15,15]
+	MusicSurroundLevelEQ EQType = "MusicSurroundLevel" // for music, in range [-15,15]
+	SurroundModeEQ       EQType = "SurroundMode"       // for music; 0 for ambient, 1 for full
+	HeightChannelLevelEQ EQType = "HeightChannelLevel" // in range [-10,10]
+	SubCrossoverEQ       EQType = "SubCrossover"       // for Amp, in Hz, in range [50,110]
+)
+
+// CurrentEQ returns the device's current equalisation settings.
+func (d *Device) CurrentEQ(ctx context.Context) (EQPreset, error) {
+	var resp struct {
+		CurrentBass     string // i2
+		CurrentTreble   string // i2
+		CurrentLoudness string // bool
+	}
+	for _, action := range []string{"GetBass", "GetTreble"} {
+		err := d.soap(ctx, av1.URN_RenderingControl_1, action, struct {
+			InstanceID string
+		}{InstanceID: "0"}, &resp)
+		if err != nil {
+			return EQPreset{}, fmt.Errorf("getting EQ: %w", err)
+		}
+	}
+	err := d.soap(ctx, av1.URN_RenderingControl_1, "GetLoudness", struct {
+		InstanceID string
+		Channel    string
+	}{
+		InstanceID: "0",
+		Channel:    "Master",
+	}, &resp)
+	if err != nil {
+		return EQPreset{}, fmt.Errorf("getting loudness: %w", err)
+	}
+	var p EQPreset
+	if p.Bass, err = strconv.Atoi(resp.CurrentBass); err != nil {
+		return EQPreset{}, fmt.Errorf("parsing bass %q: %w", resp.CurrentBass, err)
+	}
+	if p.Treble, err = strconv.Atoi(resp.CurrentTreble); err != nil {
+		return EQPreset{}, fmt.Errorf("parsing treble %q: %w", resp.CurrentTreble, err)
+	}
+	p.Loudness = resp.CurrentLoudness == "1"
+
+	left, err := d.ChannelVolume(ctx, LeftChannel)
+	if err != nil {
+		return EQPreset{}, err
+	}
+	right, err := d.ChannelVolume(ctx, RightChannel)
+	if err != nil {
+		return EQPreset{}, err
+	}
+	p.Balance = right - left
+
+	for _, f := range []struct {
+		typ EQType
+		set func(int)
+	}{
+		{NightModeEQ, func(v int) { b := v != 0; p.NightMode = &b }},
+		{SubGainEQ, func(v int) { p.SubLevel = &v }},
+		{SurroundLevelEQ, func(v int) { p.SurroundLevel = &v }},
+		{HeightChannelLevelEQ, func(v int) { p.HeightLevel = &v }},
+	} {
+		v, err := d.EQ(ctx, f.typ)
+		var uerr *UPnPError
+		if errors.As(err, &uerr) {
+			// The device lacks the setting, or the speakers involved are not bonded.
+			continue
+		} else if err != nil {
+			return EQPreset{}, err
+		}
+		f.set(v)
+	}
+	return p, nil
+}
+
+// ApplyEQ sets the device's equalisation settings.
+func (d *Device) ApplyEQ(ctx context.Context, p EQPreset) error {
+	err := d.soap(ctx, av1.URN_RenderingControl_1, "SetBass", struct {
+		InstanceID  string
+		DesiredBass string
+	}{
+		InstanceID:  "0",
+		DesiredBass: strconv.Itoa(max(-10, min(p.Bass, 10))),
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting bass: %w", err)
+	}
+	err = d.soap(ctx, av1.URN_RenderingControl_1, "SetTreble", struct {
+		InstanceID    string
+		DesiredTreble string
+	}{
+		InstanceID:    "0",
+		DesiredTreble: strconv.Itoa(max(-10, min(p.Treble, 10))),
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting treble: %w", err)
+	}
+	err = d.soap(ctx, av1.URN_RenderingControl_1, "SetLoudness", struct {
+		InstanceID      string
+		Channel         string
+		DesiredLoudness string
+	}{
+		InstanceID:      "0",
+		Channel:         "Master",
+		DesiredLoudness: boolString(p.Loudness),
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting loudness: %w", err)
+	}
+
+	// Balance is achieved by turning down one side.
+	balance := max(-100, min(p.Balance, 100))
+	if err := d.SetChannelVolume(ctx, LeftChannel, 100-max(balance, 0)); err != nil {
+		return err
+	}
+	if err := d.SetChannelVolume(ctx, RightChannel, 100+min(balance, 0)); err != nil {
+		return err
+	}
+
+	if p.NightMode != nil {
+		v := 0
+		if *p.NightMode {
+			v = 1
+		}
+		if err := d.SetEQ(ctx, NightModeEQ, v); err != nil {
+			return err
+		}
+	}
+	if p.SubLevel != nil {
+		if err := d.SetEQ(ctx, SubGainEQ, *p.SubLevel); err != nil {
+			return err
+		}
+	}
+	if p.SurroundLevel != nil {
+		if err := d.SetEQ(ctx, SurroundLevelEQ, *p.SurroundLevel); err != nil {
+			return err
+		}
+	}
+	if p.HeightLevel != nil {
+		if err := d.SetEQ(ctx, HeightChannelLevelEQ, *p.HeightLevel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EQ returns one of the device's extended EQ settings.
+// Settings that the device lacks give a *UPnPError.
+func (d *Device) EQ(ctx context.Context, typ EQType) (int, error) {
+	var resp struct {
+		CurrentValue string // i2
+	}
+	err := d.soap(ctx, av1.URN_RenderingControl_1, "GetEQ", struct {
+		InstanceID string
+		EQType     string
+	}{
+		InstanceID: "0",
+		EQType:     string(typ),
+	}, &resp)
+	if err != nil {
+		return 0, fmt.Errorf("getting %s: %w", typ, err)
+	}
+	v, err := strconv.Atoi(resp.CurrentValue)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s %q: %w", typ, resp.CurrentValue, err)
+	}
+	return v, nil
+}
+
+// SetEQ changes one of the device's extended EQ settings.
+func (d *Device) SetEQ(ctx context.Context, typ EQType, value int) error {
+	err := d.soap(ctx, av1.URN_RenderingControl_1, "SetEQ", struct {
+		InstanceID   string
+		EQType       string
+		DesiredValue string
+	}{
+		InstanceID:   "0",
+		EQType:       string(typ),
+		DesiredValue: strconv.Itoa(value),
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting %s: %w", typ, err)
+	}
+	return nil
+}
+
+// TrueplayStatus reports a device's Trueplay room tuning.
+type TrueplayStatus struct {
+	Available bool // whether the device has been tuned for its room
+	Enabled   bool // whether the tuning is applied
+}
+
+// Trueplay returns the state of the device's Trueplay tuning.
+func (d *Device) Trueplay(ctx context.Context) (TrueplayStatus, error) {
+	var resp struct {
+		RoomCalibrationEnabled   string // bool
+		RoomCalibrationAvailable string // bool
+	}
+	err := d.soap(ctx, av1.URN_RenderingControl_1, "GetRoomCalibrationStatus", struct {
+		InstanceID string
+	}{InstanceID: "0"}, &resp)
+	if err != nil {
+		return TrueplayStatus{}, fmt.Errorf("getting room calibration status: %w", err)
+	}
+	return TrueplayStatus{
+		Available: resp.RoomCalibrationAvailable == "1",
+		Enabled:   resp.RoomCalibrationEnabled == "1",
+	}, nil
+}
+
+// SetTrueplay applies or removes the device's Trueplay tuning, if it has one.
+func (d *Device) SetTrueplay(ctx context.Context, enabled bool) error {
+	err := d.soap(ctx, av1.URN_RenderingControl_1, "SetRoomCalibrationStatus", struct {
+		InstanceID             string
+		RoomCalibrationEnabled string
+	}{
+		InstanceID:             "0",
+		RoomCalibrationEnabled: boolString(enabled),
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting room calibration status: %w", err)
+	}
+	return nil
+}
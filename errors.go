@@ -0,0 +1,142 @@
+package sonos
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/huin/goupnp/soap"
+)
+
+// UPnPError is an error reported by a device in response to a SOAP action.
+// Use errors.As to find one in an error returned by this package.
+type UPnPError struct {
+	Service     string // e.g. AVTransportService
+	Action      string // e.g. "Play"
+	Code        int    // e.g. 701; see the ErrCode constants
+	Description string // often empty
+}
+
+func (e *UPnPError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("UPnP error %d in %s: %s", e.Code, e.Action, e.Description)
+	}
+	return fmt.Sprintf("UPnP error %d in %s", e.Code, e.Action)
+}
+
+// Some UPnP error codes. Codes below 600 mean the same for every service.
+// Codes in the 700s are defined per service by the UPnP AV specs, and these are
+// AVTransport's; codes in the 800s are specific to Sonos.
+const (
+	ErrCodeInvalidAction           = 401
+	ErrCodeInvalidArgs             = 402
+	ErrCodeActionFailed            = 501
+	ErrCodeTransitionNotAvailable  = 701
+	ErrCodeNoContents              = 702
+	ErrCodeReadError               = 703
+	ErrCodeIllegalSeekTarget       = 711
+	ErrCodeIllegalMIMEType         = 714
+	ErrCodeResourceNotFound        = 716
+	ErrCodeInvalidInstanceID       = 718
+	ErrCodeNotCoordinator          = 800
+	ErrCodeSonosServiceUnavailable = 804
+)
+
+// ErrorCode returns the UPnP error code in err (see the ErrCode constants),
+// or 0 if there is none. It is useful for labelling failures, such as in a Tracer.
+func ErrorCode(err error) int {
+	var ue *UPnPError
+	if !errors.As(err, &ue) {
+		return 0
+	}
+	return ue.Code
+}
+
+// explanations are guidance for users for UPnP error codes, by service.
+// Codes below 600 are the same for every service, so they are under "".
+var explanations = map[string]map[int]string{
+	"": {
+		ErrCodeInvalidAction: "The device does not support this; it may need a software update.",
+		ErrCodeInvalidArgs:   "The device rejected the request's arguments; check that values are in range.",
+		ErrCodeActionFailed:  "The device could not do this right now; try again shortly.",
+	},
+	AVTransportService: {
+		ErrCodeTransitionNotAvailable:  "The device cannot do this in its current state, e.g. pausing a radio stream or playing an empty queue.",
+		ErrCodeNoContents:              "There is nothing to play; add something to the queue first.",
+		ErrCodeReadError:               "The device could not read the media; check that the share or file is still available.",
+		ErrCodeIllegalSeekTarget:       "That position is not in the track or queue.",
+		ErrCodeIllegalMIMEType:         "The device cannot play this kind of media or URI.",
+		ErrCodeResourceNotFound:        "The media could not be found; it may have moved or been removed.",
+		ErrCodeInvalidInstanceID:       "The device rejected the instance ID; this is a bug in the caller.",
+		ErrCodeNotCoordinator:          "This zone is grouped with another; control the group's coordinator instead, or ungroup it.",
+		ErrCodeSonosServiceUnavailable: "The music service is unavailable; check its account in the Sonos app.",
+	},
+	ContentDirectoryService: {
+		701: "That item or container does not exist; it may have been removed.",
+		710: "That container does not exist; it may have been removed.",
+	},
+}
+
+// An ErrorExplainer turns errors from this package into guidance for users,
+// such as for showing in a command-line tool or a chat bot.
+// The zero value has explanations for the ErrCode constants,
+// for the services that report them.
+type ErrorExplainer struct {
+	// Messages adds or replaces explanations, by UPnP error code.
+	// They apply whichever service reported the error.
+	Messages map[int]string
+}
+
+// Explain returns guidance for err, or the empty string if there is none.
+func (e *ErrorExplainer) Explain(err error) string {
+	var ue *UnreachableError
+	if errors.As(err, &ue) {
+		return "The device did not respond; check that it is powered on and on the same network."
+	}
+	var pe *UPnPError
+	if !errors.As(err, &pe) {
+		return ""
+	}
+	if msg, ok := e.Messages[pe.Code]; ok {
+		return msg
+	}
+	if pe.Code < 600 {
+		return explanations[""][pe.Code]
+	}
+	return explanations[pe.Service][pe.Code]
+}
+
+// Explain returns guidance for err using the default ErrorExplainer,
+// or the empty string if there is none.
+func Explain(err error) string {
+	return (&ErrorExplainer{}).Explain(err)
+}
+
+// upnpError converts a SOAP fault into a *UPnPError, if possible.
+// Other errors are returned unchanged.
+func upnpError(service, action string, err error) error {
+	var fault *soap.SOAPFaultError
+	if !errors.As(err, &fault) {
+		return err
+	}
+	// The detail holds a single <UPnPError> element.
+	var detail struct {
+		Code        string `xml:"errorCode"`
+		Description string `xml:"errorDescription"`
+	}
+	if xml.Unmarshal(fault.Detail.Raw, &detail) != nil {
+		return err
+	}
+	code, cerr := strconv.Atoi(strings.TrimSpace(detail.Code))
+	if cerr != nil {
+		return err
+	}
+	return &UPnPError{
+		Service:     service,
+		Action:      action,
+		Code:        code,
+		Description: strings.TrimSpace(detail.Description),
+	}
+}
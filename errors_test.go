@@ -0,0 +1,65 @@
+package sonos
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dsymonds/sonos/sonostest"
+)
+
+func TestUPnPError(t *testing.T) {
+	ctx := context.Background()
+	fake := newFake(t, "Kitchen")
+	fake.Handle("Play", func(map[string]string) (map[string]string, error) {
+		return nil, sonostest.Fault(ErrCodeTransitionNotAvailable)
+	})
+	fake.Handle("Browse", func(map[string]string) (map[string]string, error) {
+		return nil, sonostest.Fault(701) // no such object
+	})
+	c := newTestClient(t, nil, fake)
+	d := device(t, c, fake)
+
+	err := d.Play(ctx)
+	if code := ErrorCode(err); code != ErrCodeTransitionNotAvailable {
+		t.Fatalf("Play = %v, want error %d", err, ErrCodeTransitionNotAvailable)
+	}
+	if got := Explain(err); !strings.Contains(got, "current state") {
+		t.Errorf("Explain(%v) = %q, want the AVTransport explanation", err, got)
+	}
+
+	// The same code means something else from the ContentDirectory.
+	_, err = d.Queue(ctx)
+	if code := ErrorCode(err); code != 701 {
+		t.Fatalf("Queue = %v, want error 701", err)
+	}
+	if got := Explain(err); !strings.Contains(got, "does not exist") {
+		t.Errorf("Explain(%v) = %q, want the ContentDirectory explanation", err, got)
+	}
+
+	ex := &ErrorExplainer{Messages: map[int]string{701: "Nope."}}
+	if got := ex.Explain(err); got != "Nope." {
+		t.Errorf("Explain with Messages = %q, want %q", got, "Nope.")
+	}
+}
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string // substring, or "" for no explanation
+	}{
+		{&UPnPError{Service: RenderingControlService, Action: "SetVolume", Code: ErrCodeInvalidArgs}, "arguments"},
+		{&UPnPError{Service: AVTransportService, Action: "Play", Code: ErrCodeNotCoordinator}, "coordinator"},
+		{&UPnPError{Service: RenderingControlService, Action: "SetEQ", Code: ErrCodeResourceNotFound}, ""},
+		{&UnreachableError{}, "did not respond"},
+		{context.Canceled, ""},
+	}
+	for _, tc := range tests {
+		got := Explain(tc.err)
+		if tc.want == "" && got != "" {
+			t.Errorf("Explain(%v) = %q, want none", tc.err, got)
+		} else if !strings.Contains(got, tc.want) {
+			t.Errorf("Explain(%v) = %q, want it to mention %q", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/huin/goupnp/dcps/av1"
+)
+
+// An Event is a change reported by Device.Watch or Client.Watch.
+// It is one of *DeviceEvent, *TopologyEvent, *LibraryEvent,
+// *TransportEvent, *TrackEvent or *VolumeEvent.
+type Event interface {
+	event()
+}
+
+func (*DeviceEvent) event()    {}
+func (*TopologyEvent) event()  {}
+func (*LibraryEvent) event()   {}
+func (*TransportEvent) event() {}
+func (*TrackEvent) event()     {}
+func (*VolumeEvent) event()    {}
+
+// TopologyEvent reports a change in how the household's zones are grouped.
+type TopologyEvent struct {
+	Groups []ZoneGroup
+}
+
+// LibraryEvent reports a change in the household's music library, Sonos playlists
+// or favorites, or in whether the library is being reindexed.
+// Cached views of the changed containers should be refreshed.
+type LibraryEvent struct {
+	UpdateID int // the ContentDirectory's system update ID, which increases with each change
+	Indexing bool
+
+	// Containers are the IDs of the containers that changed, of
+	// "A:" (the music library), "SQ:" (Sonos playlists) and "FV:2" (favorites).
+	// It may be empty if something else changed, or only Indexing did.
+	Containers []string
+}
+
+// TransportEvent reports a change in a device's transport state.
+type TransportEvent struct {
+	UDN   string
+	State TransportState
+}
+
+// TrackEvent reports a change in the track that a device is playing.
+type TrackEvent struct {
+	UDN      string
+	Position PositionInfo
+}
+
+// VolumeEvent reports a change in a device's volume or mute.
+type VolumeEvent struct {
+	UDN    string
+	Volume int
+	Mute   bool
+}
+
+// libraryContainers are the containers whose changes a LibraryEvent reports.
+var libraryContainers = []string{"A:", sonosPlaylistsID, favoritesID}
+
+// libraryState is the state of the library that Client.Watch checks.
+type libraryState struct {
+	updateID   int
+	indexing   bool
+	containers map[string]int // update IDs, by container ID
+}
+
+// changes returns an event for the changes from old to s, or nil if there are none.
+func (s libraryState) changes(old libraryState) *LibraryEvent {
+	ev := &LibraryEvent{UpdateID: s.updateID, Indexing: s.indexing}
+	for _, id := range libraryContainers {
+		if s.containers[id] != old.containers[id] {
+			ev.Containers = append(ev.Containers, id)
+		}
+	}
+	if len(ev.Containers) == 0 && s.updateID == old.updateID && s.indexing == old.indexing {
+		return nil
+	}
+	return ev
+}
+
+// libraryState returns the ContentDirectory's system update ID, whether it is
+// indexing music shares, and the update IDs of the library containers.
+func (d *Device) libraryState(ctx context.Context) (libraryState, error) {
+	var id struct {
+		Id string // ui4
+	}
+	err := d.soap(ctx, av1.URN_ContentDirectory_1, "GetSystemUpdateID", struct{}{}, &id)
+	if err != nil {
+		return libraryState{}, fmt.Errorf("getting system update ID: %w", err)
+	}
+	var idx struct {
+		IsIndexing string // bool
+	}
+	err = d.soap(ctx, av1.URN_ContentDirectory_1, "GetShareIndexInProgress", struct{}{}, &idx)
+	if err != nil {
+		return libraryState{}, fmt.Errorf("getting share index state: %w", err)
+	}
+	s := libraryState{indexing: idx.IsIndexing == "1", containers: make(map[string]int)}
+	if s.updateID, err = strconv.Atoi(id.Id); err != nil {
+		return libraryState{}, fmt.Errorf("parsing system update ID %q: %w", id.Id, err)
+	}
+	for _, cid := range libraryContainers {
+		if s.containers[cid], err = d.containerUpdateID(ctx, cid); err != nil {
+			return libraryState{}, err
+		}
+	}
+	return s, nil
+}
+
+// containerUpdateID returns the update ID of a ContentDirectory container,
+// which changes whenever the container's contents do.
+// These are what UPnP events report as ContainerUpdateIDs.
+func (d *Device) containerUpdateID(ctx context.Context, objectID string) (int, error) {
+	var resp struct {
+		Result         string // DIDL-Lite XML
+		NumberReturned string // ui4
+		TotalMatches   string // ui4
+		UpdateID       string // ui4
+	}
+	err := d.soap(ctx, av1.URN_ContentDirectory_1, "Browse", struct {
+		ObjectID       string
+		BrowseFlag     string
+		Filter         string
+		StartingIndex  string
+		RequestedCount string
+		SortCriteria   string
+	}{
+		ObjectID:       objectID,
+		BrowseFlag:     "BrowseDirectChildren",
+		Filter:         "dc:title",
+		StartingIndex:  "0",
+		RequestedCount: "1",
+	}, &resp)
+	if err != nil {
+		return 0, fmt.Errorf("browsing %s: %w", objectID, err)
+	}
+	n, err := strconv.Atoi(resp.UpdateID)
+	if err != nil {
+		return 0, fmt.Errorf("parsing update ID %q of %s: %w", resp.UpdateID, objectID, err)
+	}
+	return n, nil
+}
+
+// libraryState returns the state of the household's library, as seen by any device.
+func (c *Client) libraryState(ctx context.Context) (libraryState, error) {
+	var lastErr error
+	for _, d := range c.Devices() {
+		if len(d.dev.FindService(av1.URN_ContentDirectory_1)) == 0 {
+			continue
+		}
+		ev, err := d.libraryState(ctx)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return ev, nil
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no device provides a content directory")
+	}
+	return libraryState{}, lastErr
+}
+
+// Watch polls the device for changes to what it is playing, and reports them
+// on the returned channel until ctx is done.
+// It sends a *TransportEvent, *TrackEvent and *VolumeEvent first with the initial state,
+// and then only when those change.
+// Polling needs no connections from the device back to the caller, as UPnP event
+// subscriptions would; this package does not use those. See WithPollInterval.
+// The channel is closed once ctx is done, and must be drained.
+func (d *Device) Watch(ctx context.Context) <-chan Event {
+	ch := make(chan Event)
+	go func() {
+		defer close(ch)
+		t := time.NewTicker(d.c.pollInterval())
+		defer t.Stop()
+
+		var last *deviceSample
+		for {
+			var evs []Event
+			cur, err := d.sample(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				d.logger().WarnContext(ctx, "Polling device", "err", err)
+			} else {
+				if last == nil || cur.state != last.state {
+					evs = append(evs, &TransportEvent{UDN: d.dev.UDN, State: cur.state})
+				}
+				if last == nil || !sameTrack(cur.pos, last.pos) {
+					evs = append(evs, &TrackEvent{UDN: d.dev.UDN, Position: cur.pos})
+				}
+				if last == nil || cur.vol != last.vol {
+					vol := cur.vol
+					evs = append(evs, &vol)
+				}
+				last = &cur
+			}
+			for _, ev := range evs {
+				select {
+				case ch <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+	return ch
+}
+
+// deviceSample is the state of a device that Device.Watch checks.
+type deviceSample struct {
+	state TransportState
+	pos   PositionInfo
+	vol   VolumeEvent
+}
+
+func (d *Device) sample(ctx context.Context) (deviceSample, error) {
+	pe, err := d.playback(ctx)
+	if err != nil {
+		return deviceSample{}, err
+	}
+	s := deviceSample{
+		state: pe.State,
+		pos:   pe.Position,
+		vol:   VolumeEvent{UDN: d.dev.UDN},
+	}
+	if s.vol.Volume, err = d.Volume(ctx); err != nil {
+		return deviceSample{}, err
+	}
+	if s.vol.Mute, err = d.Mute(ctx); err != nil {
+		return deviceSample{}, err
+	}
+	return s, nil
+}
+
+// pollInterval returns how often to poll devices for changes.
+// c may be nil.
+func (c *Client) pollInterval() time.Duration {
+	if c == nil {
+		return 2 * time.Second
+	}
+	return or(c.opts.pollInterval, 2*time.Second)
+}
+
+// or returns d, or def if d is not positive.
+func or(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+// topologyKey summarises zone groups, for detecting changes.
+func topologyKey(groups []ZoneGroup) string {
+	var gs []string
+	for _, g := range groups {
+		var ms []string
+		for _, m := range g.Members {
+			ms = append(ms, m.UUID)
+		}
+		sort.Strings(ms)
+		gs = append(gs, fmt.Sprintf("%s:%s", g.Coordinator, strings.Join(ms, ",")))
+	}
+	sort.Strings(gs)
+	return strings.Join(gs, ";")
+}
@@ -0,0 +1,174 @@
+// Package exporter publishes the state of a Sonos system as Prometheus metrics.
+//
+// It writes the Prometheus text exposition format directly,
+// so it does not depend on the Prometheus client libraries.
+//
+//	http.Handle("/metrics", exporter.Handler(client))
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/dsymonds/sonos"
+)
+
+// Handler returns an HTTP handler that serves metrics for every zone known to the client.
+// The state is gathered afresh on every request.
+func Handler(c *sonos.Client) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		if err := Write(r.Context(), &buf, c); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write(buf.Bytes())
+	})
+}
+
+type metric struct {
+	name, help string
+	samples    []sample
+}
+
+type sample struct {
+	labels [][2]string
+	value  float64
+}
+
+// Write writes metrics for every zone known to the client.
+// Zones that cannot be queried are logged and skipped.
+func Write(ctx context.Context, w io.Writer, c *sonos.Client) error {
+	zones, err := c.Zones(ctx)
+	if err != nil {
+		return fmt.Errorf("listing zones: %w", err)
+	}
+
+	var (
+		up          = &metric{name: "sonos_up", help: "Whether the zone could be queried."}
+		volume      = &metric{name: "sonos_volume", help: "Volume of the zone, in range [0,100]."}
+		muted       = &metric{name: "sonos_muted", help: "Whether the zone is muted."}
+		transport   = &metric{name: "sonos_transport_state", help: "Playback state of the zone; 1 for the current state."}
+		group       = &metric{name: "sonos_group_info", help: "Group membership of the zone; always 1."}
+		battery     = &metric{name: "sonos_battery_level", help: "Battery charge of the zone, in percent."}
+		charging    = &metric{name: "sonos_battery_charging", help: "Whether the zone's battery is on external power."}
+		nowPlaying  = &metric{name: "sonos_now_playing_info", help: "What the zone is playing; always 1."}
+		allMetrics  = []*metric{up, volume, muted, transport, group, battery, charging, nowPlaying}
+		coordinator = make(map[string]string) // zone UUID -> group coordinator zone name
+	)
+
+	// Group membership comes from the topology, as seen by any zone.
+	uuidZone := make(map[string]string)
+	for _, z := range zones {
+		uuidZone[z.Coordinator] = z.Name
+	}
+	for _, z := range zones {
+		dev, err := c.ZoneDevice(ctx, z.Name)
+		if err != nil {
+			continue
+		}
+		groups, err := dev.ZoneGroups(ctx)
+		if err != nil {
+			continue
+		}
+		for _, g := range groups {
+			for _, m := range g.Members {
+				coordinator[m.UUID] = uuidZone[g.Coordinator]
+			}
+		}
+		break
+	}
+
+	for _, z := range zones {
+		zl := [2]string{"zone", z.Name}
+		dev, err := c.ZoneDevice(ctx, z.Name)
+		if err == nil {
+			err = collectZone(ctx, dev, zl, volume, muted, transport, battery, charging, nowPlaying)
+		}
+		if err != nil {
+			c.Logger().WarnContext(ctx, "Collecting metrics", "zone", z.Name, "err", err)
+			up.samples = append(up.samples, sample{labels: [][2]string{zl}, value: 0})
+			continue
+		}
+		up.samples = append(up.samples, sample{labels: [][2]string{zl}, value: 1})
+		if coord, ok := coordinator[z.Coordinator]; ok {
+			group.samples = append(group.samples, sample{labels: [][2]string{zl, {"coordinator", coord}}, value: 1})
+		}
+	}
+
+	for _, m := range allMetrics {
+		if len(m.samples) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", m.name, m.help, m.name)
+		for _, s := range m.samples {
+			var ls []string
+			for _, l := range s.labels {
+				ls = append(ls, l[0]+`="`+escapeLabel(l[1])+`"`)
+			}
+			fmt.Fprintf(w, "%s{%s} %g\n", m.name, strings.Join(ls, ","), s.value)
+		}
+	}
+	return nil
+}
+
+var transportStates = []sonos.TransportState{sonos.PausedPlayback, sonos.Playing, sonos.Stopped, sonos.Transitioning}
+
+func collectZone(ctx context.Context, dev *sonos.Device, zl [2]string, volume, muted, transport, battery, charging, nowPlaying *metric) error {
+	vol, err := dev.Volume(ctx)
+	if err != nil {
+		return err
+	}
+	mute, err := dev.Mute(ctx)
+	if err != nil {
+		return err
+	}
+	state, err := dev.TransportState(ctx)
+	if err != nil {
+		return err
+	}
+	pi, err := dev.PositionInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	volume.samples = append(volume.samples, sample{labels: [][2]string{zl}, value: float64(vol)})
+	muted.samples = append(muted.samples, sample{labels: [][2]string{zl}, value: boolValue(mute)})
+	for _, s := range transportStates {
+		transport.samples = append(transport.samples, sample{
+			labels: [][2]string{zl, {"state", string(s)}},
+			value:  boolValue(s == state),
+		})
+	}
+	nowPlaying.samples = append(nowPlaying.samples, sample{
+		labels: [][2]string{zl, {"title", pi.Title}, {"artist", pi.Creator}, {"album", pi.Album}},
+		value:  1,
+	})
+
+	bs, err := dev.BatteryStatus(ctx)
+	if errors.Is(err, sonos.ErrNoBattery) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	battery.samples = append(battery.samples, sample{labels: [][2]string{zl}, value: float64(bs.Level)})
+	charging.samples = append(charging.samples, sample{labels: [][2]string{zl}, value: boolValue(bs.Charging)})
+	return nil
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
@@ -0,0 +1,60 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dsymonds/sonos"
+	"github.com/dsymonds/sonos/sonostest"
+)
+
+func TestWrite(t *testing.T) {
+	ctx := context.Background()
+	lead := sonostest.NewDevice("Living Room")
+	defer lead.Close()
+	member := sonostest.NewDevice(`Kid's "Den"`)
+	defer member.Close()
+	sonostest.Group(lead, member)
+	c, err := sonos.NewClientFromIPs(ctx, nil)
+	if err != nil {
+		t.Fatalf("NewClientFromIPs: %v", err)
+	}
+	for _, fake := range []*sonostest.Device{lead, member} {
+		if err := c.AddDeviceByURL(ctx, fake.Location()); err != nil {
+			t.Fatalf("AddDeviceByURL: %v", err)
+		}
+	}
+	d, err := c.ZoneDevice(ctx, "Living Room")
+	if err != nil {
+		t.Fatalf("ZoneDevice: %v", err)
+	}
+	if err := d.Play(ctx); err != nil {
+		t.Fatalf("Play: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(ctx, &buf, c); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE sonos_volume gauge\n",
+		`sonos_up{zone="Living Room"} 1` + "\n",
+		`sonos_volume{zone="Living Room"} 20` + "\n",
+		`sonos_muted{zone="Living Room"} 0` + "\n",
+		`sonos_transport_state{zone="Living Room",state="PLAYING"} 1` + "\n",
+		`sonos_transport_state{zone="Living Room",state="STOPPED"} 0` + "\n",
+		`sonos_group_info{zone="Living Room",coordinator="Living Room"} 1` + "\n",
+		`sonos_up{zone="Kid's \"Den\""} 1` + "\n",
+		`sonos_group_info{zone="Kid's \"Den\"",coordinator="Living Room"} 1` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q; got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "sonos_battery_level") {
+		t.Errorf("output has battery metrics for devices without batteries:\n%s", out)
+	}
+}
@@ -0,0 +1,93 @@
+package sonos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// favoritesID is the ContentDirectory object holding Sonos favorites.
+const favoritesID = "FV:2"
+
+// Favorite is an entry in My Sonos, the household's favorites.
+type Favorite struct {
+	ID          string // e.g. "FV:2/13"
+	Title       string
+	URI         string
+	Metadata    string // DIDL-Lite XML for playing the URI
+	AlbumArtURI string // often relative; see Device.AlbumArtURL
+}
+
+// Favorites returns the household's Sonos favorites.
+func (d *Device) Favorites(ctx context.Context) ([]Favorite, error) {
+	var favs []Favorite
+	for {
+		result, n, total, err := d.browse(ctx, favoritesID, len(favs))
+		if err != nil {
+			return nil, err
+		}
+		didl, err := parseDIDL(result)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range didl.Items {
+			favs = append(favs, Favorite{
+				ID:          it.ID,
+				Title:       it.Title,
+				URI:         it.uri(),
+				Metadata:    it.ResMD,
+				AlbumArtURI: it.AlbumArt,
+			})
+		}
+		if n == 0 || len(favs) >= total {
+			return favs, nil
+		}
+	}
+}
+
+// FindFavorite returns the Sonos favorite with the given title.
+func (d *Device) FindFavorite(ctx context.Context, title string) (Favorite, error) {
+	favs, err := d.Favorites(ctx)
+	if err != nil {
+		return Favorite{}, err
+	}
+	for _, f := range favs {
+		if f.Title == title {
+			return f, nil
+		}
+	}
+	return Favorite{}, fmt.Errorf("no Sonos favorite %q", title)
+}
+
+// PlayFavorite plays a Sonos favorite. Favorites of albums and playlists
+// replace the queue of the device's group; others, such as radio stations, are played directly.
+func (d *Device) PlayFavorite(ctx context.Context, f Favorite) error {
+	if !isContainerURI(f.URI) {
+		return d.PlayStream(ctx, ServiceItem{URI: f.URI, Metadata: f.Metadata})
+	}
+	coord, err := d.coordinator(ctx)
+	if err != nil {
+		return err
+	}
+	if err := coord.ClearQueue(ctx); err != nil {
+		return err
+	}
+	if _, err := coord.addURIToQueue(ctx, f.URI, f.Metadata, EnqueueOptions{}); err != nil {
+		return fmt.Errorf("adding %q to queue: %w", f.Title, err)
+	}
+	if err := coord.selectQueue(ctx); err != nil {
+		return err
+	}
+	return coord.Play(ctx)
+}
+
+// isContainerURI reports whether a URI refers to a collection of tracks,
+// which must be added to a queue rather than played directly.
+func isContainerURI(uri string) bool {
+	for _, prefix := range []string{"x-rincon-cpcontainer:", "x-rincon-playlist:", "file:///jffs/settings/savedqueues.rsq"} {
+		if strings.HasPrefix(uri, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package sonos
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FileServer serves local audio files over HTTP so that devices can play them.
+type FileServer struct {
+	ln  net.Listener
+	srv *http.Server
+
+	mu    sync.Mutex
+	files map[string]string // path, by token
+}
+
+// NewFileServer starts a FileServer listening on addr (e.g. ":8080").
+// If addr is empty, it listens on a random port on all interfaces.
+// The caller should call Close when finished.
+func NewFileServer(addr string) (*FileServer, error) {
+	if addr == "" {
+		addr = ":0"
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listening for file server: %w", err)
+	}
+	fs := &FileServer{
+		ln:    ln,
+		files: make(map[string]string),
+	}
+	fs.srv = &http.Server{Handler: http.HandlerFunc(fs.serveHTTP)}
+	go fs.srv.Serve(ln)
+	return fs, nil
+}
+
+// Close stops the server.
+func (fs *FileServer) Close() error { return fs.srv.Close() }
+
+func (fs *FileServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	token, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	fs.mu.Lock()
+	path, ok := fs.files[token]
+	fs.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		http.Error(w, "file unavailable", http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		http.Error(w, "file unavailable", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", audioMIMEType(path))
+	http.ServeContent(w, r, fi.Name(), fi.ModTime(), f)
+}
+
+// Item makes a local file available to the device, returning an item
+// that it can play or add to its queue. The file must remain in place
+// while it is in use.
+func (fs *FileServer) Item(d *Device, path string) (ServiceItem, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return ServiceItem{}, err
+	}
+	if fi, err := os.Stat(path); err != nil {
+		return ServiceItem{}, err
+	} else if !fi.Mode().IsRegular() {
+		return ServiceItem{}, fmt.Errorf("%s is not a regular file", path)
+	}
+	host, err := fs.hostFor(d)
+	if err != nil {
+		return ServiceItem{}, err
+	}
+
+	var tok [8]byte
+	if _, err := rand.Read(tok[:]); err != nil {
+		return ServiceItem{}, err
+	}
+	token := hex.EncodeToString(tok[:])
+	fs.mu.Lock()
+	fs.files[token] = path
+	fs.mu.Unlock()
+
+	name := filepath.Base(path)
+	u := &url.URL{Scheme: "http", Host: host, Path: "/" + token + "/" + name}
+	title := strings.TrimSuffix(name, filepath.Ext(name))
+	metadata := trackMetadata(u.String(), audioMIMEType(path), "object.item.audioItem.musicTrack", title, "")
+	return ServiceItem{URI: u.String(), Metadata: metadata}, nil
+}
+
+// hostFor returns the host:port at which the device can reach the server.
+// It uses the local address that routes to the device.
+func (fs *FileServer) hostFor(d *Device) (string, error) {
+	ip := d.IP()
+	if ip == nil {
+		return "", errors.New("device has no IP address")
+	}
+	// Dialing UDP sends nothing, but picks the local address.
+	conn, err := net.Dial("udp", net.JoinHostPort(ip.String(), "1400"))
+	if err != nil {
+		return "", fmt.Errorf("finding route to device: %w", err)
+	}
+	defer conn.Close()
+	local := conn.LocalAddr().(*net.UDPAddr).IP
+	_, port, err := net.SplitHostPort(fs.ln.Addr().String())
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(local.String(), port), nil
+}
+
+// audioMIMEType returns the MIME type of an audio file, judging by its extension.
+func audioMIMEType(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp3":
+		return "audio/mpeg"
+	case ".flac":
+		return "audio/flac"
+	case ".m4a", ".aac":
+		return "audio/mp4"
+	case ".ogg":
+		return "audio/ogg"
+	case ".wav":
+		return "audio/wav"
+	}
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// PlayFile plays a local audio file on the device, served by fs.
+// This replaces what the device was playing; it does not use the queue.
+func (d *Device) PlayFile(ctx context.Context, fs *FileServer, path string) error {
+	item, err := fs.Item(d, path)
+	if err != nil {
+		return err
+	}
+	return d.PlayStream(ctx, item)
+}
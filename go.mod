@@ -1,8 +1,7 @@
 module github.com/dsymonds/sonos
 
-go 1.19
+go 1.23
 
-require (
-	github.com/huin/goupnp v1.0.3 // indirect
-	golang.org/x/sync v0.1.0 // indirect
-)
+require github.com/huin/goupnp v1.0.3
+
+require golang.org/x/sync v0.1.0 // indirect
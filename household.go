@@ -0,0 +1,102 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// coordinators returns the devices that coordinate the household's groups.
+func (c *Client) coordinators(ctx context.Context) ([]*Device, error) {
+	groups, err := c.zoneGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byUUID := make(map[string]*Device)
+	for _, d := range c.Devices() {
+		byUUID[d.uid()] = d
+	}
+	var devs []*Device
+	for _, g := range groups {
+		if d, ok := byUUID[g.Coordinator]; ok {
+			devs = append(devs, d)
+		}
+	}
+	return devs, nil
+}
+
+// PauseAll pauses every group in the household that is playing,
+// and remembers which they were for ResumeAll.
+// Groups playing streams, which cannot be paused, are stopped instead.
+func (c *Client) PauseAll(ctx context.Context) error {
+	devs, err := c.coordinators(ctx)
+	if err != nil {
+		return err
+	}
+	paused := make([]bool, len(devs))
+	errs := make([]error, len(devs))
+	var wg sync.WaitGroup
+	for i, d := range devs {
+		wg.Add(1)
+		go func(i int, d *Device) {
+			defer wg.Done()
+			state, err := d.TransportState(ctx)
+			if err != nil || (state != Playing && state != Transitioning) {
+				errs[i] = err
+				return
+			}
+			err = d.Pause(ctx)
+			if ErrorCode(err) == ErrCodeTransitionNotAvailable {
+				err = d.Stop(ctx)
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", d.RoomName(), err)
+				return
+			}
+			paused[i] = true
+		}(i, d)
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused == nil {
+		c.paused = make(map[string]bool)
+	}
+	for i, d := range devs {
+		if paused[i] {
+			c.paused[d.dev.UDN] = true
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ResumeAll resumes the groups that PauseAll paused.
+// Groups that were not playing before PauseAll stay as they are.
+func (c *Client) ResumeAll(ctx context.Context) error {
+	c.mu.Lock()
+	paused := c.paused
+	c.paused = nil
+	c.mu.Unlock()
+
+	var devs []*Device
+	for _, d := range c.Devices() {
+		if paused[d.dev.UDN] {
+			devs = append(devs, d)
+		}
+	}
+	errs := make([]error, len(devs))
+	var wg sync.WaitGroup
+	for i, d := range devs {
+		wg.Add(1)
+		go func(i int, d *Device) {
+			defer wg.Done()
+			if err := d.Play(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", d.RoomName(), err)
+			}
+		}(i, d)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
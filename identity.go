@@ -0,0 +1,123 @@
+package sonos
+
+import (
+	"net"
+)
+
+// meta returns what the device's client knows about it, or nil.
+func (d *Device) meta() *deviceMeta {
+	if d.c == nil {
+		return nil
+	}
+	d.c.mu.Lock()
+	defer d.c.mu.Unlock()
+	return d.c.meta[d.dev.UDN]
+}
+
+// RoomName returns the name of the room that the device is in.
+func (d *Device) RoomName() string {
+	if d.c == nil {
+		return ""
+	}
+	d.c.mu.Lock()
+	defer d.c.mu.Unlock()
+	if zone := d.c.zoneOf(d.dev.UDN); zone != "" {
+		return zone
+	}
+	if m := d.c.meta[d.dev.UDN]; m != nil {
+		return m.desc.RoomName
+	}
+	return ""
+}
+
+// RoomIcon returns the icon of the room that the device is in
+// (e.g. "x-rincon-roomicon:living"), if the device's client knows it.
+func (d *Device) RoomIcon() string {
+	if d.c == nil {
+		return ""
+	}
+	d.c.mu.Lock()
+	defer d.c.mu.Unlock()
+	if m := d.c.meta[d.dev.UDN]; m != nil {
+		return m.icon
+	}
+	return ""
+}
+
+// ModelName returns the device's model name (e.g. "Sonos One").
+func (d *Device) ModelName() string { return d.dev.ModelName }
+
+// ModelNumber returns the device's model number (e.g. "S18").
+func (d *Device) ModelNumber() string { return d.dev.ModelNumber }
+
+// DisplayName returns the device's short model name, as shown in the Sonos app (e.g. "One").
+func (d *Device) DisplayName() string {
+	if m := d.meta(); m != nil {
+		return m.desc.DisplayName
+	}
+	return ""
+}
+
+// UDN returns the device's UPnP unique device name (e.g. "uuid:RINCON_000E58123456789").
+func (d *Device) UDN() string { return d.dev.UDN }
+
+// UUID returns the device's unique ID (e.g. "RINCON_000E58123456789").
+func (d *Device) UUID() string { return d.uid() }
+
+// Generation is a generation of Sonos firmware.
+// Households run one or the other, and the generations support different features.
+type Generation int
+
+const (
+	UnknownGeneration Generation = iota
+	S1
+	S2
+)
+
+func (g Generation) String() string {
+	switch g {
+	case S1:
+		return "S1"
+	case S2:
+		return "S2"
+	}
+	return "unknown"
+}
+
+// Generation reports which generation of firmware the device runs.
+func (d *Device) Generation() Generation {
+	m := d.meta()
+	if m == nil {
+		return UnknownGeneration
+	}
+	return m.desc.generation()
+}
+
+func (desc description) generation() Generation {
+	switch desc.SWGen {
+	case "1":
+		return S1
+	case "2":
+		return S2
+	}
+	return UnknownGeneration
+}
+
+// IP returns the device's IP address, or nil if it is unknown.
+func (d *Device) IP() net.IP {
+	u, err := d.baseURL()
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(u.Hostname())
+}
+
+// IconURL returns the URL of the device's icon, or the empty string if it has none.
+func (d *Device) IconURL() string {
+	for _, icon := range d.upnp().Icons {
+		if icon.URL.Ok {
+			return icon.URL.URL.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,186 @@
+package sonos
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// QueueFormat is a file format for queues.
+type QueueFormat int
+
+const (
+	M3U  QueueFormat = iota // extended M3U, in UTF-8 (i.e. M3U8)
+	JSON                    // an array of objects with "title", "creator", "album", "uri" and "metadata"
+)
+
+// ExportQueue writes the device's queue to w in the given format.
+func (d *Device) ExportQueue(ctx context.Context, w io.Writer, format QueueFormat) error {
+	items, err := d.Queue(ctx)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case M3U:
+		return writeM3U(w, items)
+	case JSON:
+		return writeQueueJSON(w, items)
+	}
+	return fmt.Errorf("unknown queue format %d", format)
+}
+
+func writeM3U(w io.Writer, items []QueueItem) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("#EXTM3U\n")
+	for _, it := range items {
+		title := it.Title
+		if it.Creator != "" {
+			title = it.Creator + " - " + title
+		}
+		// Titles can't span lines.
+		title = strings.Join(strings.Fields(title), " ")
+		fmt.Fprintf(bw, "#EXTINF:-1,%s\n%s\n", title, it.URI)
+	}
+	return bw.Flush()
+}
+
+// queueJSONItem is the JSON form of a QueueItem.
+type queueJSONItem struct {
+	Title    string `json:"title"`
+	Creator  string `json:"creator,omitempty"`
+	Album    string `json:"album,omitempty"`
+	URI      string `json:"uri"`
+	Metadata string `json:"metadata,omitempty"`
+}
+
+func writeQueueJSON(w io.Writer, items []QueueItem) error {
+	js := make([]queueJSONItem, len(items))
+	for i, it := range items {
+		js[i] = queueJSONItem{
+			Title:    it.Title,
+			Creator:  it.Creator,
+			Album:    it.Album,
+			URI:      it.URI,
+			Metadata: it.Metadata,
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(js)
+}
+
+// ImportM3U adds the entries of an M3U or M3U8 playlist to the end of the device's queue.
+// Entries may be URIs that Sonos understands, HTTP URLs of audio files or
+// internet radio streams, or local files, as paths or file: URLs, which fs serves;
+// fs may be nil if there are no local files.
+// Relative paths are resolved against dir, which is normally the playlist's directory;
+// if dir is empty, they are resolved against the current directory.
+func (d *Device) ImportM3U(ctx context.Context, r io.Reader, dir string, fs *FileServer) (EnqueueResult, error) {
+	var (
+		res   EnqueueResult
+		title string // from the preceding #EXTINF
+		dur   = -1   // from the preceding #EXTINF
+	)
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		entry := strings.TrimSpace(sc.Text())
+		if line == 1 {
+			entry = strings.TrimPrefix(entry, "\ufeff") // byte order mark
+		}
+		if entry == "" {
+			continue
+		}
+		if info, ok := strings.CutPrefix(entry, "#EXTINF:"); ok {
+			ds, t, _ := strings.Cut(info, ",")
+			if _, err := fmt.Sscan(ds, &dur); err != nil {
+				dur = -1
+			}
+			title = strings.TrimSpace(t)
+			continue
+		}
+		if strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		item, err := m3uItem(d, fs, dir, entry, title, dur)
+		if err != nil {
+			return res, fmt.Errorf("line %d: %w", line, err)
+		}
+		er, err := d.AddToQueue(ctx, item, EnqueueOptions{})
+		if err != nil {
+			return res, fmt.Errorf("line %d: %w", line, err)
+		}
+		if res.FirstTrack == 0 {
+			res.FirstTrack = er.FirstTrack
+		}
+		res.TracksAdded += er.TracksAdded
+		res.QueueLength = er.QueueLength
+		title, dur = "", -1
+	}
+	if err := sc.Err(); err != nil {
+		return res, fmt.Errorf("reading playlist: %w", err)
+	}
+	return res, nil
+}
+
+// m3uItem converts an M3U entry into something to add to a queue.
+func m3uItem(d *Device, fs *FileServer, dir, entry, title string, dur int) (ServiceItem, error) {
+	scheme, _, hasScheme := strings.Cut(entry, ":")
+	scheme = strings.ToLower(scheme)
+	if !hasScheme || len(scheme) == 1 || scheme == "file" { // a path, perhaps with a Windows drive letter
+		path := entry
+		if scheme == "file" {
+			u, err := url.Parse(entry)
+			if err != nil {
+				return ServiceItem{}, err
+			}
+			if u.Host != "" && u.Host != "localhost" {
+				return ServiceItem{}, fmt.Errorf("file %q is on another host", entry)
+			}
+			path = filepath.FromSlash(u.Path)
+			if len(path) > 1 && filepath.VolumeName(path[1:]) != "" {
+				path = path[1:] // file:///C:/... on Windows
+			}
+		}
+		if fs == nil {
+			return ServiceItem{}, fmt.Errorf("local file %q needs a file server", entry)
+		}
+		if !filepath.IsAbs(path) && dir != "" {
+			path = filepath.Join(dir, path)
+		}
+		return fs.Item(d, path)
+	}
+	if scheme != "http" && scheme != "https" {
+		// Presumably something Sonos understands, such as from ExportQueue.
+		return ServiceItem{URI: entry}, nil
+	}
+
+	var creator string
+	if a, t, ok := strings.Cut(title, " - "); ok {
+		creator, title = a, t
+	}
+	u, err := url.Parse(entry)
+	if err != nil {
+		return ServiceItem{}, err
+	}
+	mimeType := audioMIMEType(u.Path)
+	if dur <= 0 && mimeType == "application/octet-stream" {
+		// Probably an internet radio stream.
+		if title == "" {
+			title = entry
+		}
+		return RadioStreamItem(entry, title)
+	}
+	if title == "" {
+		title = entry
+	}
+	return ServiceItem{
+		URI:      entry,
+		Metadata: trackMetadata(entry, mimeType, "object.item.audioItem.musicTrack", title, creator),
+	}, nil
+}
@@ -0,0 +1,95 @@
+package sonos
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportM3U(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.mp3", filepath.Join("sub", "b.flac")} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("audio "+name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fs, err := NewFileServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("NewFileServer: %v", err)
+	}
+	defer fs.Close()
+
+	fake := newFake(t, "Kitchen")
+	c := newTestClient(t, nil, fake)
+	d := device(t, c, fake)
+
+	fileURL := (&url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(dir, "sub", "b.flac"))}).String()
+	playlist := "\ufeff#EXTM3U\n" +
+		"#EXTINF:215,Artist - Song\n" +
+		"http://example.com/song.mp3\n" +
+		"\n" +
+		"a.mp3\n" +
+		fileURL + "\n" +
+		"# a comment\n" +
+		"x-file-cifs://nas/c.mp3\n"
+	res, err := d.ImportM3U(context.Background(), strings.NewReader(playlist), dir, fs)
+	if err != nil {
+		t.Fatalf("ImportM3U: %v", err)
+	}
+	if want := (EnqueueResult{FirstTrack: 1, TracksAdded: 4, QueueLength: 4}); res != want {
+		t.Errorf("ImportM3U = %+v, want %+v", res, want)
+	}
+
+	var got []string
+	for _, call := range fake.Calls() {
+		if call.Action == "AddURIToQueue" {
+			got = append(got, call.Args["EnqueuedURI"])
+		}
+	}
+	if len(got) != 4 {
+		t.Fatalf("enqueued %q, want 4 URIs", got)
+	}
+	if got[0] != "http://example.com/song.mp3" || got[3] != "x-file-cifs://nas/c.mp3" {
+		t.Errorf("enqueued %q", got)
+	}
+	for i, name := range map[int]string{1: "a.mp3", 2: filepath.Join("sub", "b.flac")} {
+		resp, err := http.Get(got[i])
+		if err != nil {
+			t.Errorf("fetching %s: %v", got[i], err)
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if want := "audio " + name; string(body) != want {
+			t.Errorf("%s served %q, want %q", got[i], body, want)
+		}
+	}
+}
+
+func TestM3UItem(t *testing.T) {
+	fake := newFake(t, "Kitchen")
+	c := newTestClient(t, nil, fake)
+	d := device(t, c, fake)
+
+	item, err := m3uItem(d, nil, "", "http://radio.example.com/live", "", -1)
+	if err != nil {
+		t.Fatalf("m3uItem of stream: %v", err)
+	}
+	if !strings.HasPrefix(item.URI, "x-rincon-mp3radio:") {
+		t.Errorf("stream URI = %q, want x-rincon-mp3radio", item.URI)
+	}
+	if _, err := m3uItem(d, nil, "", "file://nas/music/a.mp3", "", -1); err == nil || !strings.Contains(err.Error(), "another host") {
+		t.Errorf("m3uItem of file on another host = %v, want an error", err)
+	}
+	if _, err := m3uItem(d, nil, "", "music/a.mp3", "", -1); err == nil || !strings.Contains(err.Error(), "file server") {
+		t.Errorf("m3uItem of path without file server = %v, want an error", err)
+	}
+}
@@ -0,0 +1,140 @@
+package sonos
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	mdnsAddr    = "224.0.0.251:5353"
+	mdnsService = "_sonos._tcp.local."
+)
+
+// mdnsSearch looks for devices advertising the _sonos._tcp mDNS service,
+// and returns the locations of their device descriptions.
+//
+// It sends a "legacy unicast" query (RFC 6762 section 6.7) from an ephemeral port,
+// so every response that comes back is an answer to the query,
+// and the device's address is the source of the response.
+func mdnsSearch(ctx context.Context, o options) ([]*url.URL, error) {
+	deadline := searchDeadline(ctx, o)
+	addrs, err := multicastAddrs(o.iface)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("no usable network interfaces")
+	}
+
+	var (
+		mu   sync.Mutex
+		seen = make(map[string]bool)
+		locs []*url.URL
+		errs []error
+	)
+	var wg sync.WaitGroup
+	for _, addr := range addrs {
+		addr := addr
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ips, err := mdnsSearchAddr(ctx, addr, deadline)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("querying mDNS from %s: %w", addr, err))
+			}
+			for _, ip := range ips {
+				if seen[ip.String()] {
+					continue
+				}
+				seen[ip.String()] = true
+				locs = append(locs, &url.URL{
+					Scheme: "http",
+					Host:   net.JoinHostPort(ip.String(), "1400"),
+					Path:   "/xml/device_description.xml",
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Whatever was found before ctx was done is still worth returning.
+	if len(locs) == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+	}
+	return locs, nil
+}
+
+// mdnsSearchAddr performs an mDNS query from a single local address,
+// and returns the addresses of the devices that answered.
+func mdnsSearchAddr(ctx context.Context, laddr net.IP, deadline time.Time) ([]net.IP, error) {
+	conn, err := net.ListenPacket("udp4", net.JoinHostPort(laddr.String(), "0"))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	defer stopOnDone(ctx, conn)()
+
+	dest, err := net.ResolveUDPAddr("udp4", mdnsAddr)
+	if err != nil {
+		return nil, err
+	}
+	id := uint16(rand.Intn(1 << 16))
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	if _, err := conn.WriteTo(mdnsQuery(id, mdnsService), dest); err != nil {
+		return nil, err
+	}
+
+	var ips []net.IP
+	buf := make([]byte, 9000)
+	for {
+		n, src, err := conn.ReadFrom(buf)
+		if err != nil {
+			var nerr net.Error
+			if errors.As(err, &nerr) && nerr.Timeout() {
+				return ips, nil
+			}
+			return ips, err
+		}
+		// Check the header: matching ID, is a response, has answers.
+		msg := buf[:n]
+		if len(msg) < 12 || binary.BigEndian.Uint16(msg[0:]) != id ||
+			msg[2]&0x80 == 0 || binary.BigEndian.Uint16(msg[6:]) == 0 {
+			continue
+		}
+		if ua, ok := src.(*net.UDPAddr); ok {
+			ips = append(ips, ua.IP)
+		}
+	}
+}
+
+// mdnsQuery returns a DNS query message asking for PTR records for name.
+func mdnsQuery(id uint16, name string) []byte {
+	msg := make([]byte, 12, 64)
+	binary.BigEndian.PutUint16(msg[0:], id)
+	binary.BigEndian.PutUint16(msg[4:], 1) // QDCOUNT
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0)
+	msg = binary.BigEndian.AppendUint16(msg, 12) // QTYPE: PTR
+	msg = binary.BigEndian.AppendUint16(msg, 1)  // QCLASS: IN
+	return msg
+}
@@ -0,0 +1,268 @@
+// Package mqtt bridges a Sonos system to an MQTT broker.
+//
+// The state of each zone is published as JSON to "<prefix>/<zone>/state",
+// where <zone> is the zone name in lower case with everything but letters and digits
+// replaced by underscores (so "Living Room" becomes "living_room").
+// Commands are accepted on:
+//
+//	<prefix>/<zone>/command     "play", "pause" or "stop"
+//	<prefix>/<zone>/volume/set  volume in range [0,100]
+//	<prefix>/<zone>/mute/set    "ON" or "OFF"
+//
+// Entities are announced using the Home Assistant MQTT discovery convention,
+// so zones appear in Home Assistant automatically.
+//
+// This package does not depend on any particular MQTT client library;
+// callers provide a Conn, which is easy to implement on top of any of them.
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dsymonds/sonos"
+)
+
+// A Conn is a connection to an MQTT broker.
+type Conn interface {
+	Publish(topic string, payload []byte, retain bool) error
+	// Subscribe arranges for handler to be called for each message on the topic.
+	Subscribe(topic string, handler func(topic string, payload []byte)) error
+}
+
+// Bridge publishes zone state to MQTT, and applies commands received from MQTT.
+type Bridge struct {
+	Client *sonos.Client
+	Conn   Conn
+
+	Prefix          string        // topic prefix; default "sonos"
+	DiscoveryPrefix string        // Home Assistant discovery prefix; default "homeassistant"
+	Interval        time.Duration // how often to publish state; default 10s
+}
+
+// ZoneState is the JSON published to each zone's state topic.
+type ZoneState struct {
+	State  string `json:"state"` // e.g. "PLAYING"
+	Volume int    `json:"volume"`
+	Muted  bool   `json:"muted"`
+	Title  string `json:"title,omitempty"`
+	Artist string `json:"artist,omitempty"`
+	Album  string `json:"album,omitempty"`
+}
+
+func (b *Bridge) prefix() string {
+	if b.Prefix == "" {
+		return "sonos"
+	}
+	return b.Prefix
+}
+
+func (b *Bridge) discoveryPrefix() string {
+	if b.DiscoveryPrefix == "" {
+		return "homeassistant"
+	}
+	return b.DiscoveryPrefix
+}
+
+// Run announces the zones, subscribes to their command topics,
+// and publishes their state periodically until ctx is done.
+func (b *Bridge) Run(ctx context.Context) error {
+	zones, err := b.Client.Zones(ctx)
+	if err != nil {
+		return fmt.Errorf("listing zones: %w", err)
+	}
+	slugs := make(map[string]string)
+	for _, z := range zones {
+		if other, ok := slugs[slug(z.Name)]; ok {
+			return fmt.Errorf("zones %q and %q would share the topic %s; rename one of them", other, z.Name, b.topic(z.Name))
+		}
+		slugs[slug(z.Name)] = z.Name
+	}
+	for _, z := range zones {
+		if err := b.announce(z); err != nil {
+			return err
+		}
+		if err := b.subscribe(ctx, z.Name); err != nil {
+			return err
+		}
+	}
+
+	interval := b.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		for _, z := range zones {
+			if err := b.publishState(ctx, z.Name); err != nil {
+				b.Client.Logger().WarnContext(ctx, "Publishing zone state", "zone", z.Name, "err", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// slug returns a form of the zone name suitable for MQTT topics and entity IDs.
+// Different names may have the same slug; Run refuses to start if two zones do.
+func slug(zone string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '_'
+	}, zone)
+}
+
+func (b *Bridge) topic(zone string, parts ...string) string {
+	return strings.Join(append([]string{b.prefix(), slug(zone)}, parts...), "/")
+}
+
+func (b *Bridge) publishState(ctx context.Context, zone string) error {
+	dev, err := b.Client.ZoneDevice(ctx, zone)
+	if err != nil {
+		return err
+	}
+	var zs ZoneState
+	state, err := dev.TransportState(ctx)
+	if err != nil {
+		return err
+	}
+	zs.State = string(state)
+	if zs.Volume, err = dev.Volume(ctx); err != nil {
+		return err
+	}
+	if zs.Muted, err = dev.Mute(ctx); err != nil {
+		return err
+	}
+	pi, err := dev.PositionInfo(ctx)
+	if err != nil {
+		return err
+	}
+	zs.Title, zs.Artist, zs.Album = pi.Title, pi.Creator, pi.Album
+
+	payload, err := json.Marshal(zs)
+	if err != nil {
+		return err
+	}
+	return b.Conn.Publish(b.topic(zone, "state"), payload, true)
+}
+
+func (b *Bridge) subscribe(ctx context.Context, zone string) error {
+	handle := func(what string, f func(dev *sonos.Device, payload string) error) func(string, []byte) {
+		return func(_ string, payload []byte) {
+			dev, err := b.Client.ZoneDevice(ctx, zone)
+			if err == nil {
+				err = f(dev, strings.TrimSpace(string(payload)))
+			}
+			if err != nil {
+				b.Client.Logger().WarnContext(ctx, "Handling MQTT message", "kind", what, "zone", zone, "err", err)
+				return
+			}
+			if err := b.publishState(ctx, zone); err != nil {
+				b.Client.Logger().WarnContext(ctx, "Publishing zone state", "zone", zone, "err", err)
+			}
+		}
+	}
+	subs := map[string]func(string, []byte){
+		b.topic(zone, "command"): handle("command", func(dev *sonos.Device, cmd string) error {
+			switch strings.ToLower(cmd) {
+			case "play":
+				return dev.Play(ctx)
+			case "pause":
+				return dev.Pause(ctx)
+			case "stop":
+				return dev.Stop(ctx)
+			}
+			return fmt.Errorf("unknown command %q", cmd)
+		}),
+		b.topic(zone, "volume", "set"): handle("volume", func(dev *sonos.Device, vol string) error {
+			v, err := strconv.Atoi(vol)
+			if err != nil {
+				return fmt.Errorf("bad volume %q: %w", vol, err)
+			}
+			return dev.SetVolume(ctx, v)
+		}),
+		b.topic(zone, "mute", "set"): handle("mute", func(dev *sonos.Device, mute string) error {
+			return dev.SetMute(ctx, strings.EqualFold(mute, "ON"))
+		}),
+	}
+	for topic, h := range subs {
+		if err := b.Conn.Subscribe(topic, h); err != nil {
+			return fmt.Errorf("subscribing to %s: %w", topic, err)
+		}
+	}
+	return nil
+}
+
+// announce publishes Home Assistant discovery configuration for the zone's entities.
+func (b *Bridge) announce(z sonos.Zone) error {
+	id := "sonos_" + slug(z.Name)
+	device := map[string]interface{}{
+		"identifiers":  []string{z.Coordinator},
+		"name":         z.Name,
+		"manufacturer": "Sonos",
+	}
+	state := b.topic(z.Name, "state")
+	entities := []struct {
+		component, object string
+		config            map[string]interface{}
+	}{
+		{"sensor", "state", map[string]interface{}{
+			"name":           "State",
+			"state_topic":    state,
+			"value_template": "{{ value_json.state }}",
+		}},
+		{"sensor", "title", map[string]interface{}{
+			"name":           "Now playing",
+			"state_topic":    state,
+			"value_template": "{{ value_json.title }}",
+		}},
+		{"number", "volume", map[string]interface{}{
+			"name":           "Volume",
+			"state_topic":    state,
+			"value_template": "{{ value_json.volume }}",
+			"command_topic":  b.topic(z.Name, "volume", "set"),
+			"min":            0,
+			"max":            100,
+		}},
+		{"switch", "mute", map[string]interface{}{
+			"name":           "Mute",
+			"state_topic":    state,
+			"value_template": "{{ 'ON' if value_json.muted else 'OFF' }}",
+			"command_topic":  b.topic(z.Name, "mute", "set"),
+		}},
+		{"button", "play", map[string]interface{}{
+			"name":          "Play",
+			"command_topic": b.topic(z.Name, "command"),
+			"payload_press": "play",
+		}},
+		{"button", "pause", map[string]interface{}{
+			"name":          "Pause",
+			"command_topic": b.topic(z.Name, "command"),
+			"payload_press": "pause",
+		}},
+	}
+	for _, e := range entities {
+		e.config["unique_id"] = id + "_" + e.object
+		e.config["device"] = device
+		payload, err := json.Marshal(e.config)
+		if err != nil {
+			return err
+		}
+		topic := strings.Join([]string{b.discoveryPrefix(), e.component, id, e.object, "config"}, "/")
+		if err := b.Conn.Publish(topic, payload, true); err != nil {
+			return fmt.Errorf("publishing %s: %w", topic, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package mqtt
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dsymonds/sonos"
+	"github.com/dsymonds/sonos/sonostest"
+)
+
+// recorder is a Conn that records the topics published to.
+type recorder struct{ topics []string }
+
+func (r *recorder) Publish(topic string, payload []byte, retain bool) error {
+	r.topics = append(r.topics, topic)
+	return nil
+}
+
+func (r *recorder) Subscribe(topic string, handler func(topic string, payload []byte)) error {
+	return nil
+}
+
+func TestRunSlugCollision(t *testing.T) {
+	ctx := context.Background()
+	c, err := sonos.NewClientFromIPs(ctx, nil)
+	if err != nil {
+		t.Fatalf("NewClientFromIPs: %v", err)
+	}
+	for _, zone := range []string{"Living Room", "Living-Room"} {
+		fake := sonostest.NewDevice(zone)
+		defer fake.Close()
+		if err := c.AddDeviceByURL(ctx, fake.Location()); err != nil {
+			t.Fatalf("AddDeviceByURL: %v", err)
+		}
+	}
+	conn := new(recorder)
+	b := &Bridge{Client: c, Conn: conn}
+	err = b.Run(ctx)
+	if err == nil || !strings.Contains(err.Error(), "sonos/living_room") {
+		t.Errorf("Run returned %v, want a topic collision", err)
+	}
+	if len(conn.topics) > 0 {
+		t.Errorf("Run published %q before refusing", conn.topics)
+	}
+}
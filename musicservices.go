@@ -0,0 +1,79 @@
+package sonos
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MusicService describes a streaming service that a household can use.
+type MusicService struct {
+	ID           int // e.g. 12 for Spotify; used by SpotifyServiceItem etc.
+	Name         string
+	URI          string // SMAPI endpoint
+	SecureURI    string // SMAPI endpoint, over HTTPS
+	Capabilities int    // bit field
+	Auth         string // e.g. "Anonymous", "UserId", "DeviceLink", "AppLink"
+	PollInterval int    // seconds, if the service asks to be polled for updates
+
+	// Configured reports whether the household has an account with the service.
+	Configured bool
+}
+
+// ServiceType returns the service type of the music service,
+// as used in account descriptors in DIDL-Lite metadata.
+func (ms MusicService) ServiceType() int { return ms.ID<<8 + 7 }
+
+// MusicServices returns the music services available to the device's household.
+func (d *Device) MusicServices(ctx context.Context) ([]MusicService, error) {
+	var resp struct {
+		AvailableServiceDescriptorList string // XML
+		AvailableServiceTypeList       string // comma-separated service types
+		AvailableServiceListVersion    string
+	}
+	err := d.soap(ctx, MusicServicesService, "ListAvailableServices", struct{}{}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("listing available services: %w", err)
+	}
+
+	var list struct {
+		Services []struct {
+			ID           int    `xml:"Id,attr"`
+			Name         string `xml:"Name,attr"`
+			URI          string `xml:"Uri,attr"`
+			SecureURI    string `xml:"SecureUri,attr"`
+			Capabilities string `xml:"Capabilities,attr"`
+			Policy       struct {
+				Auth         string `xml:"Auth,attr"`
+				PollInterval int    `xml:"PollInterval,attr"`
+			} `xml:"Policy"`
+		} `xml:"Service"`
+	}
+	if err := xml.Unmarshal([]byte(resp.AvailableServiceDescriptorList), &list); err != nil {
+		return nil, fmt.Errorf("parsing service descriptors: %w", err)
+	}
+	configured := make(map[int]bool) // by service type
+	for _, f := range strings.Split(resp.AvailableServiceTypeList, ",") {
+		if st, err := strconv.Atoi(strings.TrimSpace(f)); err == nil {
+			configured[st] = true
+		}
+	}
+
+	var svcs []MusicService
+	for _, s := range list.Services {
+		ms := MusicService{
+			ID:           s.ID,
+			Name:         s.Name,
+			URI:          s.URI,
+			SecureURI:    s.SecureURI,
+			Auth:         s.Policy.Auth,
+			PollInterval: s.Policy.PollInterval,
+		}
+		ms.Capabilities, _ = strconv.Atoi(s.Capabilities)
+		ms.Configured = configured[ms.ServiceType()]
+		svcs = append(svcs, ms)
+	}
+	return svcs, nil
+}
@@ -0,0 +1,165 @@
+package sonos
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// An Option configures a Client.
+type Option func(*options)
+
+type options struct {
+	hc        *http.Client
+	logger    *slog.Logger
+	household string
+	retry     RetryPolicy
+	tracer    Tracer
+	cache     Cache
+	maxVolume int
+
+	groupVolume bool
+	noRedirect  bool
+
+	actionTimeout time.Duration
+	rateEvery     time.Duration
+	rateBurst     int
+
+	diagnostics bool
+
+	pollInterval       time.Duration
+	rediscoverInterval time.Duration
+
+	searchTimeout time.Duration
+	iface         string
+	mx            int
+	mdns          bool
+}
+
+// WithHTTPClient sets the HTTP client used for all requests to devices,
+// including SOAP actions and fetching device descriptions.
+// SSDP and mDNS discovery do not use it.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *options) { o.hc = hc }
+}
+
+// WithLogger sets the logger for a Client. The default is slog.Default().
+// Problems during discovery are logged at warning level,
+// and every SOAP action and its response is logged at debug level.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// WithRetry sets how SOAP actions are retried after transient failures.
+// By default they are not retried.
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) { o.retry = policy }
+}
+
+// WithTracer sets a Tracer to observe every SOAP action that the Client performs.
+func WithTracer(t Tracer) Option {
+	return func(o *options) { o.tracer = t }
+}
+
+// WithActionTimeout bounds how long each SOAP action may take, including any retries,
+// when the context it is called with has no deadline.
+// By default actions can wait indefinitely for a player that has hung.
+func WithActionTimeout(d time.Duration) Option {
+	return func(o *options) { o.actionTimeout = d }
+}
+
+// WithRateLimit limits how often the Client sends SOAP actions to each device,
+// since players can fail under bursts of requests.
+// Each device may be sent up to burst actions at once, and then one per interval.
+// Actions wait their turn, or until their context is done.
+func WithRateLimit(interval time.Duration, burst int) Option {
+	return func(o *options) {
+		o.rateEvery = interval
+		o.rateBurst = burst
+	}
+}
+
+// WithMaxVolume sets a ceiling on the volumes that the Client will set,
+// in range [1,100]. Requests for louder volumes are clamped to it.
+// Client.LimitVolume sets a ceiling for a single device.
+func WithMaxVolume(volume int) Option {
+	return func(o *options) { o.maxVolume = volume }
+}
+
+// WithGroupVolume makes Device.Volume and Device.SetVolume on a group coordinator
+// apply to its whole group, as the Sonos app's volume control does,
+// with the members' volumes kept in proportion.
+// By default they apply to the coordinator alone, leaving the rest of the group unchanged.
+// Device.ChannelVolume with MasterChannel always applies to a single device.
+func WithGroupVolume() Option {
+	return func(o *options) { o.groupVolume = true }
+}
+
+// WithoutCoordinatorRedirect stops a Client from redirecting transport actions
+// (e.g. Play or Next) to the coordinator of a group when a member refuses them,
+// so that they instead fail with ErrCodeNotCoordinator.
+// It also stops methods that choose what a group plays, such as Device.PlaySonosPlaylist,
+// from acting on the coordinator and its queue when called on a member.
+func WithoutCoordinatorRedirect() Option {
+	return func(o *options) { o.noRedirect = true }
+}
+
+// WithHousehold restricts a Client to the devices in the identified household.
+// This is useful when there is more than one Sonos system on the same network.
+// See Device.HouseholdID.
+func WithHousehold(id string) Option {
+	return func(o *options) { o.household = id }
+}
+
+// WithSearchTimeout bounds how long discovery waits for SSDP responses.
+// By default it waits for the MX duration (see WithMX), or until the
+// context passed to Discover is done, whichever is sooner.
+func WithSearchTimeout(d time.Duration) Option {
+	return func(o *options) { o.searchTimeout = d }
+}
+
+// WithInterface restricts discovery to the named network interface (e.g. "eth0").
+// By default all multicast-capable IPv4 interfaces are used.
+func WithInterface(name string) Option {
+	return func(o *options) { o.iface = name }
+}
+
+// WithMX sets the SSDP MX value, which is the number of seconds that devices
+// may wait before responding to a search, and thus how long the search lasts.
+// The default is 2.
+func WithMX(seconds int) Option {
+	return func(o *options) { o.mx = seconds }
+}
+
+// WithCache makes Discover use the given cache of previous discovery results.
+// A full discovery is only done if the cache is empty, or if any cached device
+// does not respond. Cached devices are still asked for their current zone names.
+// See FileCache.
+func WithCache(cache Cache) Option {
+	return func(o *options) { o.cache = cache }
+}
+
+// WithMDNS makes Discover also search for devices using mDNS,
+// for networks that filter SSDP. Newer firmware advertises the _sonos._tcp service.
+func WithMDNS() Option {
+	return func(o *options) { o.mdns = true }
+}
+
+// WithPollInterval sets how often Device.Watch and Client.Watch check for changes.
+// The default is 2 seconds.
+func WithPollInterval(d time.Duration) Option {
+	return func(o *options) { o.pollInterval = d }
+}
+
+// WithRediscoverInterval sets how often Client.Watch searches for devices
+// joining or leaving the network. The default is 1 minute.
+func WithRediscoverInterval(d time.Duration) Option {
+	return func(o *options) { o.rediscoverInterval = d }
+}
+
+// WithDiagnostics permits Device.Diagnostics, which reads the devices' status pages.
+// These are undocumented, vary between firmware versions, and are not meant for
+// routine use, so must be explicitly enabled.
+func WithDiagnostics() Option {
+	return func(o *options) { o.diagnostics = true }
+}
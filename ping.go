@@ -0,0 +1,64 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// UnreachableError reports that a device did not respond.
+type UnreachableError struct {
+	UUID string // e.g. "RINCON_000E58123456789"
+	Err  error
+}
+
+func (ue *UnreachableError) Error() string {
+	return fmt.Sprintf("device %s unreachable: %v", ue.UUID, ue.Err)
+}
+func (ue *UnreachableError) Unwrap() error { return ue.Err }
+
+// Ping checks that the device still responds, returning how long it took.
+// If the device does not respond, the error is an *UnreachableError.
+// If ctx is done first, the error is ctx's, since that says nothing about the device.
+func (d *Device) Ping(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	_, err := d.ZoneAttributes(ctx)
+	latency := time.Since(start)
+	if err != nil && ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+	var ue *UPnPError
+	if err != nil && !errors.As(err, &ue) {
+		// A SOAP fault still shows the device is alive.
+		return 0, &UnreachableError{UUID: d.uid(), Err: err}
+	}
+	return latency, nil
+}
+
+// Ping checks that every device known to the client still responds, concurrently.
+// It returns the latency of each device that responded, by UUID.
+// The returned error combines an *UnreachableError for each device that did not.
+func (c *Client) Ping(ctx context.Context) (map[string]time.Duration, error) {
+	devs := c.Devices()
+	latencies := make([]time.Duration, len(devs))
+	errs := make([]error, len(devs))
+	var wg sync.WaitGroup
+	for i, d := range devs {
+		wg.Add(1)
+		go func(i int, d *Device) {
+			defer wg.Done()
+			latencies[i], errs[i] = d.Ping(ctx)
+		}(i, d)
+	}
+	wg.Wait()
+
+	res := make(map[string]time.Duration)
+	for i, d := range devs {
+		if errs[i] == nil {
+			res[d.uid()] = latencies[i]
+		}
+	}
+	return res, errors.Join(errs...)
+}
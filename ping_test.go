@@ -0,0 +1,29 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPing(t *testing.T) {
+	fake := newFake(t, "Kitchen")
+	c := newTestClient(t, nil, fake)
+	d := device(t, c, fake)
+	if _, err := d.Ping(context.Background()); err != nil {
+		t.Errorf("Ping: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := d.Ping(ctx)
+	var ue *UnreachableError
+	if !errors.Is(err, context.Canceled) || errors.As(err, &ue) {
+		t.Errorf("Ping with a cancelled context returned %v, want context.Canceled", err)
+	}
+
+	fake.Close()
+	if _, err := d.Ping(context.Background()); !errors.As(err, &ue) {
+		t.Errorf("Ping of a closed device returned %v, want an *UnreachableError", err)
+	}
+}
@@ -0,0 +1,104 @@
+package sonos
+
+import (
+	"context"
+	"time"
+)
+
+// playbackState is what a device is playing.
+type playbackState struct {
+	State    TransportState
+	Position PositionInfo
+}
+
+// sameTrack reports whether two position infos are for the same track.
+func sameTrack(a, b PositionInfo) bool {
+	return a.Track == b.Track && a.URI == b.URI && a.Title == b.Title && a.Creator == b.Creator
+}
+
+func (d *Device) playback(ctx context.Context) (playbackState, error) {
+	state, err := d.TransportState(ctx)
+	if err != nil {
+		return playbackState{}, err
+	}
+	pos, err := d.PositionInfo(ctx)
+	if err != nil {
+		return playbackState{}, err
+	}
+	return playbackState{State: state, Position: pos}, nil
+}
+
+// Progress is how far a device is through its current track.
+type Progress struct {
+	Track    int // 1-based index in the queue, or 0 if not playing from the queue
+	Position time.Duration
+	Duration time.Duration // zero for streams
+	Playing  bool
+}
+
+// progressResync is how often PositionTicker checks the device's position,
+// between which it estimates the position from the clock.
+const progressResync = 5 * time.Second
+
+// PositionTicker reports the device's progress through its current track
+// on the returned channel at the given interval (default 1s), until ctx is done.
+// To spare the device, it only asks for the position every few seconds,
+// or at the end of a track, and otherwise advances the last position it had
+// by the time elapsed since, correcting any drift.
+// The channel is closed once ctx is done, and must be drained.
+func (d *Device) PositionTicker(ctx context.Context, interval time.Duration) <-chan Progress {
+	interval = or(interval, time.Second)
+	ch := make(chan Progress)
+	go func() {
+		defer close(ch)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		var (
+			base     Progress  // as of the last poll
+			polled   time.Time // when base was polled, or zero if it has not succeeded
+			resyncAt = max(progressResync, interval)
+		)
+		for {
+			now := time.Now()
+			p := base
+			if p.Playing {
+				p.Position += now.Sub(polled)
+			}
+			atEnd := p.Duration > 0 && p.Position >= p.Duration
+			if polled.IsZero() || now.Sub(polled) >= resyncAt || atEnd {
+				ev, err := d.playback(ctx)
+				if err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					d.logger().WarnContext(ctx, "Polling position", "err", err)
+				} else {
+					base = Progress{
+						Track:    ev.Position.Track,
+						Position: ev.Position.Position,
+						Duration: ev.Position.Duration,
+						Playing:  ev.State == Playing,
+					}
+					polled, p = now, base
+				}
+			}
+			if p.Duration > 0 {
+				p.Position = min(p.Position, p.Duration)
+			}
+
+			if !polled.IsZero() {
+				select {
+				case ch <- p:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+	return ch
+}
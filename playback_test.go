@@ -0,0 +1,22 @@
+package sonos
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPositionTickerDefaultInterval(t *testing.T) {
+	fake := newFake(t, "Kitchen")
+	c := newTestClient(t, nil, fake)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ch := device(t, c, fake).PositionTicker(ctx, 0)
+	if _, ok := <-ch; !ok {
+		t.Fatal("PositionTicker closed its channel without reporting progress")
+	}
+	cancel()
+	for range ch {
+	}
+}
@@ -0,0 +1,339 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/huin/goupnp/dcps/av1"
+)
+
+// sonosPlaylistsID is the ContentDirectory object holding Sonos playlists.
+const sonosPlaylistsID = "SQ:"
+
+// SonosPlaylist is a playlist saved in the Sonos app.
+type SonosPlaylist struct {
+	ID    string // e.g. "SQ:12"
+	Title string
+	URI   string // for adding to a queue
+}
+
+// SonosPlaylists returns all the Sonos playlists.
+func (d *Device) SonosPlaylists(ctx context.Context) ([]SonosPlaylist, error) {
+	var pls []SonosPlaylist
+	for {
+		result, n, total, err := d.browse(ctx, sonosPlaylistsID, len(pls))
+		if err != nil {
+			return nil, err
+		}
+		didl, err := parseDIDL(result)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range didl.Containers {
+			pls = append(pls, sonosPlaylist(c))
+		}
+		if n == 0 || len(pls) >= total {
+			return pls, nil
+		}
+	}
+}
+
+// SonosPlaylistTracks returns up to count tracks of the Sonos playlist with the given ID
+// (e.g. "SQ:12"), starting at the 0-based index start, along with the playlist's
+// total number of tracks. If count is zero, it returns all the tracks from start.
+func (d *Device) SonosPlaylistTracks(ctx context.Context, id string, start, count int) (tracks []QueueItem, total int, err error) {
+	if !strings.HasPrefix(id, sonosPlaylistsID) {
+		return nil, 0, fmt.Errorf("%q is not a Sonos playlist ID", id)
+	}
+	for count == 0 || len(tracks) < count {
+		result, n, t, err := d.browse(ctx, id, start+len(tracks))
+		if err != nil {
+			return nil, 0, err
+		}
+		didl, err := parseDIDL(result)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, it := range didl.Items {
+			tracks = append(tracks, queueItem(it))
+		}
+		total = t
+		if n == 0 || start+len(tracks) >= total {
+			break
+		}
+	}
+	if count > 0 && len(tracks) > count {
+		tracks = tracks[:count]
+	}
+	return tracks, total, nil
+}
+
+// PlaylistMatch is how a playlist name is matched against the titles of Sonos playlists.
+type PlaylistMatch int
+
+const (
+	MatchExact  PlaylistMatch = iota // titles must be identical
+	MatchFold                        // titles must be equal ignoring case
+	MatchPrefix                      // titles must start with the name, ignoring case; a unique full match wins
+)
+
+// PlaylistNotFoundError reports that no Sonos playlist matched a name.
+type PlaylistNotFoundError struct {
+	Name        string
+	Suggestions []string // titles of similar playlists, most similar first
+}
+
+func (e *PlaylistNotFoundError) Error() string {
+	msg := fmt.Sprintf("did not find Sonos playlist named %q", e.Name)
+	if len(e.Suggestions) > 0 {
+		msg += "; did you mean " + strings.Join(quoteAll(e.Suggestions), ", ") + "?"
+	}
+	return msg
+}
+
+// FindSonosPlaylist returns the Sonos playlist with the given title.
+// It asks the device to search for it, so that a large collection of playlists
+// need not be fetched. If that finds nothing, the playlists are browsed instead,
+// stopping at the first match.
+// If there is no match, the error is a *PlaylistNotFoundError.
+func (d *Device) FindSonosPlaylist(ctx context.Context, title string) (SonosPlaylist, error) {
+	return d.MatchSonosPlaylist(ctx, title, MatchExact)
+}
+
+// MatchSonosPlaylist returns the Sonos playlist whose title matches name.
+// Matching other than MatchExact requires fetching every playlist.
+// If more than one playlist matches, it is an error.
+// If none do, the error is a *PlaylistNotFoundError.
+func (d *Device) MatchSonosPlaylist(ctx context.Context, name string, match PlaylistMatch) (SonosPlaylist, error) {
+	if match == MatchExact {
+		pl, ok, err := d.searchSonosPlaylist(ctx, name)
+		var uerr *UPnPError
+		if ok {
+			return pl, nil
+		} else if errors.As(err, &uerr) {
+			d.logger().DebugContext(ctx, "Search unsupported; browsing playlists", "err", err)
+		} else if err != nil {
+			return SonosPlaylist{}, err
+		}
+	}
+
+	var titles []string
+	var found []SonosPlaylist
+	checked := 0
+	for {
+		result, n, total, err := d.browse(ctx, sonosPlaylistsID, checked)
+		if err != nil {
+			return SonosPlaylist{}, err
+		}
+		didl, err := parseDIDL(result)
+		if err != nil {
+			return SonosPlaylist{}, err
+		}
+		for _, c := range didl.Containers {
+			if match == MatchExact && c.Title == name {
+				return sonosPlaylist(c), nil
+			}
+			if match != MatchExact && strings.HasPrefix(strings.ToLower(c.Title), strings.ToLower(name)) {
+				found = append(found, sonosPlaylist(c))
+			}
+			titles = append(titles, c.Title)
+		}
+		checked += n
+		if n == 0 || checked >= total {
+			break
+		}
+	}
+
+	// found holds the case-insensitive prefix matches.
+	// Prefer a full match, and then an exact one.
+	var full []SonosPlaylist
+	for _, pl := range found {
+		if strings.EqualFold(pl.Title, name) {
+			full = append(full, pl)
+		}
+	}
+	if len(full) > 1 {
+		for _, pl := range full {
+			if pl.Title == name {
+				return pl, nil
+			}
+		}
+	}
+	if len(full) > 0 || match == MatchFold {
+		found = full
+	}
+	switch len(found) {
+	case 0:
+		return SonosPlaylist{}, &PlaylistNotFoundError{Name: name, Suggestions: suggest(name, titles)}
+	case 1:
+		return found[0], nil
+	}
+	var ambiguous []string
+	for _, pl := range found {
+		ambiguous = append(ambiguous, pl.Title)
+	}
+	return SonosPlaylist{}, fmt.Errorf("Sonos playlist name %q is ambiguous: matches %s", name, strings.Join(quoteAll(ambiguous), ", "))
+}
+
+// maxSuggestions is the most near-miss names that a PlaylistNotFoundError suggests.
+const maxSuggestions = 5
+
+// suggest returns the titles that are near misses for name, most similar first.
+func suggest(name string, titles []string) []string {
+	name = strings.ToLower(name)
+	type cand struct {
+		title string
+		dist  int
+	}
+	var cands []cand
+	for _, t := range titles {
+		lt := strings.ToLower(t)
+		dist := editDistance(name, lt)
+		if strings.Contains(lt, name) || strings.Contains(name, lt) {
+			dist = min(dist, 1)
+		}
+		if dist <= max(2, len(name)/3) {
+			cands = append(cands, cand{t, dist})
+		}
+	}
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].dist < cands[j].dist })
+	var out []string
+	for i := 0; i < len(cands) && i < maxSuggestions; i++ {
+		out = append(out, cands[i].title)
+	}
+	return out
+}
+
+// editDistance returns the Levenshtein distance between a and b, counting runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func quoteAll(ss []string) []string {
+	q := make([]string, len(ss))
+	for i, s := range ss {
+		q[i] = strconv.Quote(s)
+	}
+	return q
+}
+
+// PlayOptions adjust how PlaySonosPlaylist starts playback.
+type PlayOptions struct {
+	Mode   *PlayMode     // if non-nil, the play mode to set
+	Volume *int          // if non-nil, the volume to set before playing
+	Match  PlaylistMatch // how to match the playlist name
+}
+
+// PlaySonosPlaylist replaces the queue of the device's group with the named Sonos playlist,
+// and plays it from the start.
+// The queue is left alone if there is no such playlist, or it is empty.
+func (d *Device) PlaySonosPlaylist(ctx context.Context, name string, opts PlayOptions) error {
+	coord, err := d.coordinator(ctx)
+	if err != nil {
+		return err
+	}
+	pl, err := coord.MatchSonosPlaylist(ctx, name, opts.Match)
+	if err != nil {
+		return err
+	}
+	if _, total, err := coord.SonosPlaylistTracks(ctx, pl.ID, 0, 1); err != nil {
+		return err
+	} else if total == 0 {
+		return fmt.Errorf("Sonos playlist %q is empty", pl.Title)
+	}
+	if err := coord.ClearQueue(ctx); err != nil {
+		return err
+	}
+	if _, err := coord.addURIToQueue(ctx, pl.URI, "", EnqueueOptions{}); err != nil {
+		return fmt.Errorf("adding %q to queue: %w", pl.Title, err)
+	}
+	// The device may have been playing something other than its queue.
+	if err := coord.selectQueue(ctx); err != nil {
+		return err
+	}
+	if opts.Mode != nil {
+		if err := coord.SetPlayMode(ctx, *opts.Mode); err != nil {
+			return err
+		}
+	}
+	if opts.Volume != nil {
+		if err := d.SetVolume(ctx, *opts.Volume); err != nil {
+			return err
+		}
+	}
+	if err := coord.seek(ctx, "TRACK_NR", "1"); err != nil {
+		return err
+	}
+	return coord.Play(ctx)
+}
+
+// searchSonosPlaylist uses the ContentDirectory Search action to find a playlist.
+// A *UPnPError in the result means the device refused the search.
+func (d *Device) searchSonosPlaylist(ctx context.Context, title string) (pl SonosPlaylist, ok bool, err error) {
+	var resp struct {
+		Result         string // DIDL-Lite XML
+		NumberReturned string // ui4
+		TotalMatches   string // ui4
+		UpdateID       string // ui4
+	}
+	err = d.soap(ctx, av1.URN_ContentDirectory_1, "Search", struct {
+		ContainerID    string
+		SearchCriteria string
+		Filter         string
+		StartingIndex  string
+		RequestedCount string
+		SortCriteria   string
+	}{
+		ContainerID:    sonosPlaylistsID,
+		SearchCriteria: "dc:title = " + searchQuote(title),
+		Filter:         "*", // all fields
+		StartingIndex:  "0",
+		RequestedCount: strconv.Itoa(browsePageSize),
+	}, &resp)
+	if err != nil {
+		return SonosPlaylist{}, false, fmt.Errorf("searching Sonos playlists: %w", err)
+	}
+	didl, err := parseDIDL(resp.Result)
+	if err != nil {
+		return SonosPlaylist{}, false, err
+	}
+	// Check the titles, in case the device is looser in matching than asked.
+	for _, c := range didl.Containers {
+		if c.Title == title {
+			return sonosPlaylist(c), true, nil
+		}
+	}
+	return SonosPlaylist{}, false, nil
+}
+
+// searchQuote quotes s as a string in a UPnP search criteria.
+func searchQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+func sonosPlaylist(c didlObject) SonosPlaylist {
+	return SonosPlaylist{ID: c.ID, Title: c.Title, URI: c.uri()}
+}
@@ -0,0 +1,374 @@
+package sonos
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/huin/goupnp/dcps/av1"
+)
+
+// browsePageSize is how many items to request at once when browsing.
+// Sonos devices return at most 100 regardless.
+const browsePageSize = 100
+
+// browse lists the direct children of a ContentDirectory object, starting at the given index.
+// It returns the DIDL-Lite XML of the results, the number of results,
+// and the total number of children.
+func (d *Device) browse(ctx context.Context, objectID string, start int) (didl string, returned, total int, err error) {
+	var resp struct {
+		Result         string // DIDL-Lite XML
+		NumberReturned string // ui4
+		TotalMatches   string // ui4
+		UpdateID       string // ui4
+	}
+	err = d.soap(ctx, av1.URN_ContentDirectory_1, "Browse", struct {
+		ObjectID       string
+		BrowseFlag     string
+		Filter         string
+		StartingIndex  string
+		RequestedCount string
+		SortCriteria   string
+	}{
+		ObjectID:       objectID,
+		BrowseFlag:     "BrowseDirectChildren",
+		Filter:         "*", // all fields
+		StartingIndex:  strconv.Itoa(start),
+		RequestedCount: strconv.Itoa(browsePageSize),
+	}, &resp)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("browsing %s: %w", objectID, err)
+	}
+	returned, err = strconv.Atoi(resp.NumberReturned)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("parsing number returned %q: %w", resp.NumberReturned, err)
+	}
+	total, err = strconv.Atoi(resp.TotalMatches)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("parsing total matches %q: %w", resp.TotalMatches, err)
+	}
+	return resp.Result, returned, total, nil
+}
+
+// EnqueueOptions say where in a queue to add things.
+// The zero value adds to the end.
+type EnqueueOptions struct {
+	Position int  // 1-based queue position at which to insert, or 0 for the end
+	Next     bool // insert after the current track, to be played next; overrides Position
+}
+
+// addURIToQueue adds a URI, with optional DIDL-Lite metadata, to the device's queue.
+func (d *Device) addURIToQueue(ctx context.Context, uri, metadata string, opts EnqueueOptions) (EnqueueResult, error) {
+	position := opts.Position
+	if opts.Next {
+		pi, err := d.PositionInfo(ctx)
+		if err != nil {
+			return EnqueueResult{}, err
+		}
+		position = pi.Track + 1
+	}
+	var resp struct {
+		FirstTrackNumberEnqueued string // ui4
+		NumTracksAdded           string // ui4
+		NewQueueLength           string // ui4
+	}
+	err := d.soap(ctx, av1.URN_AVTransport_1, "AddURIToQueue", struct {
+		InstanceID                      string
+		EnqueuedURI                     string
+		EnqueuedURIMetaData             string
+		DesiredFirstTrackNumberEnqueued string
+		EnqueueAsNext                   string
+	}{
+		InstanceID:                      "0",
+		EnqueuedURI:                     uri,
+		EnqueuedURIMetaData:             metadata,
+		DesiredFirstTrackNumberEnqueued: strconv.Itoa(position),
+		EnqueueAsNext:                   boolString(opts.Next),
+	}, &resp)
+	if err != nil {
+		return EnqueueResult{}, fmt.Errorf("adding to queue: %w", err)
+	}
+	return parseEnqueueResult(resp.FirstTrackNumberEnqueued, resp.NumTracksAdded, resp.NewQueueLength)
+}
+
+// QueueItem is a track in a device's queue.
+type QueueItem struct {
+	Title   string
+	Creator string // usually the artist
+	Album   string
+	URI     string
+
+	AlbumArtURI string // often relative; see Device.AlbumArtURL
+
+	// Metadata is the item's DIDL-Lite XML,
+	// which is needed to add some items (e.g. from music services) to a queue.
+	Metadata string
+}
+
+// Queue returns the tracks in the device's queue, in order.
+func (d *Device) Queue(ctx context.Context) ([]QueueItem, error) {
+	var items []QueueItem
+	for {
+		result, n, total, err := d.browse(ctx, "Q:0", len(items))
+		if err != nil {
+			return nil, err
+		}
+		didl, err := parseDIDL(result)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range didl.Items {
+			items = append(items, queueItem(it))
+		}
+		if n == 0 || len(items) >= total {
+			return items, nil
+		}
+	}
+}
+
+func queueItem(it didlObject) QueueItem {
+	return QueueItem{
+		Title:   it.Title,
+		Creator: it.Creator,
+		Album:   it.Album,
+		URI:     it.uri(),
+
+		AlbumArtURI: it.AlbumArt,
+
+		Metadata: it.metadata(),
+	}
+}
+
+// removeTrackRange removes n tracks from the device's queue, starting at the 1-based position start.
+func (d *Device) removeTrackRange(ctx context.Context, start, n int) error {
+	var resp struct {
+		NewUpdateID string // ui4
+	}
+	err := d.soap(ctx, av1.URN_AVTransport_1, "RemoveTrackRangeFromQueue", struct {
+		InstanceID     string
+		UpdateID       string
+		StartingIndex  string
+		NumberOfTracks string
+	}{
+		InstanceID:     "0",
+		UpdateID:       "0", // don't check for concurrent changes
+		StartingIndex:  strconv.Itoa(start),
+		NumberOfTracks: strconv.Itoa(n),
+	}, &resp)
+	if err != nil {
+		return fmt.Errorf("removing tracks %d-%d from queue: %w", start, start+n-1, err)
+	}
+	return nil
+}
+
+// DedupeQueue removes tracks from the device's queue whose URIs duplicate earlier tracks,
+// keeping the first of each. It returns how many tracks were removed.
+func (d *Device) DedupeQueue(ctx context.Context) (int, error) {
+	items, err := d.Queue(ctx)
+	if err != nil {
+		return 0, err
+	}
+	seen := make(map[string]bool)
+	dup := make([]bool, len(items))
+	for i, it := range items {
+		dup[i] = seen[it.URI]
+		seen[it.URI] = true
+	}
+
+	// Remove runs of duplicates from the end, so earlier positions stay valid.
+	removed := 0
+	for i := len(items) - 1; i >= 0; i-- {
+		if !dup[i] {
+			continue
+		}
+		end := i
+		for i > 0 && dup[i-1] {
+			i--
+		}
+		if err := d.removeTrackRange(ctx, i+1, end-i+1); err != nil {
+			return removed, err
+		}
+		removed += end - i + 1
+	}
+	return removed, nil
+}
+
+// ShuffleQueue reorders the tracks in the device's queue randomly.
+// Unlike the Shuffle play mode, the new order is kept in the queue itself,
+// so it survives saving the queue as a playlist or copying it elsewhere.
+// It moves one track at a time, so takes up to one action per track.
+func (d *Device) ShuffleQueue(ctx context.Context) error {
+	items, err := d.Queue(ctx)
+	if err != nil {
+		return err
+	}
+	perm := rand.Perm(len(items))
+	cur := make([]int, len(items)) // cur[i] is the original index of the track now at position i+1
+	for i := range cur {
+		cur[i] = i
+	}
+	for i, want := range perm {
+		j := slices.Index(cur, want) // always at or after i
+		if j == i {
+			continue
+		}
+		if err := d.moveTrack(ctx, j+1, i+1); err != nil {
+			return err
+		}
+		cur = slices.Insert(slices.Delete(cur, j, j+1), i, want)
+	}
+	return nil
+}
+
+// moveTrack moves the track at the 1-based position from in the device's queue
+// to be before the track at position before.
+func (d *Device) moveTrack(ctx context.Context, from, before int) error {
+	err := d.soap(ctx, av1.URN_AVTransport_1, "ReorderTracksInQueue", struct {
+		InstanceID     string
+		StartingIndex  string
+		NumberOfTracks string
+		InsertBefore   string
+		UpdateID       string
+	}{
+		InstanceID:     "0",
+		StartingIndex:  strconv.Itoa(from),
+		NumberOfTracks: "1",
+		InsertBefore:   strconv.Itoa(before),
+		UpdateID:       "0", // don't check for concurrent changes
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("moving track %d in queue: %w", from, err)
+	}
+	return nil
+}
+
+// maxURIsPerAdd is the most URIs that a device accepts in one AddMultipleURIsToQueue action.
+const maxURIsPerAdd = 16
+
+// AddItemsToQueue adds several items to the device's queue, in order,
+// using far fewer actions than adding them one at a time.
+// Each item must be a single track or stream; containers such as albums must be added alone.
+func (d *Device) AddItemsToQueue(ctx context.Context, items []ServiceItem, opts EnqueueOptions) (EnqueueResult, error) {
+	position := opts.Position
+	if opts.Next {
+		pi, err := d.PositionInfo(ctx)
+		if err != nil {
+			return EnqueueResult{}, err
+		}
+		position = pi.Track + 1
+	}
+	var res EnqueueResult
+	for len(items) > 0 {
+		batch := items[:min(len(items), maxURIsPerAdd)]
+		items = items[len(batch):]
+
+		// The URIs and their metadata are each joined with spaces,
+		// so URIs must not contain any.
+		uris := make([]string, len(batch))
+		metadata := make([]string, len(batch))
+		for i, it := range batch {
+			uris[i] = strings.ReplaceAll(it.URI, " ", "%20")
+			metadata[i] = it.Metadata
+		}
+		pos := position
+		if pos > 0 {
+			pos += res.TracksAdded
+		}
+		var resp struct {
+			FirstTrackNumberEnqueued string // ui4
+			NumTracksAdded           string // ui4
+			NewQueueLength           string // ui4
+			NewUpdateID              string // ui4
+		}
+		err := d.soap(ctx, av1.URN_AVTransport_1, "AddMultipleURIsToQueue", struct {
+			InstanceID                      string
+			UpdateID                        string
+			NumberOfURIs                    string
+			EnqueuedURIs                    string
+			EnqueuedURIsMetaData            string
+			ContainerURI                    string
+			ContainerMetaData               string
+			DesiredFirstTrackNumberEnqueued string
+			EnqueueAsNext                   string
+		}{
+			InstanceID:                      "0",
+			UpdateID:                        "0", // don't check for concurrent changes
+			NumberOfURIs:                    strconv.Itoa(len(batch)),
+			EnqueuedURIs:                    strings.Join(uris, " "),
+			EnqueuedURIsMetaData:            strings.Join(metadata, " "),
+			DesiredFirstTrackNumberEnqueued: strconv.Itoa(pos),
+			EnqueueAsNext:                   boolString(opts.Next),
+		}, &resp)
+		if err != nil {
+			return res, fmt.Errorf("adding to queue: %w", err)
+		}
+		er, err := parseEnqueueResult(resp.FirstTrackNumberEnqueued, resp.NumTracksAdded, resp.NewQueueLength)
+		if err != nil {
+			return res, err
+		}
+		if res.FirstTrack == 0 {
+			res.FirstTrack = er.FirstTrack
+		}
+		res.TracksAdded += er.TracksAdded
+		res.QueueLength = er.QueueLength
+	}
+	return res, nil
+}
+
+// MatchTitleOrCreator returns a function for PlayQueueTrack that matches
+// queue items whose title or creator contains s, ignoring case.
+func MatchTitleOrCreator(s string) func(QueueItem) bool {
+	s = strings.ToLower(s)
+	return func(it QueueItem) bool {
+		return strings.Contains(strings.ToLower(it.Title), s) || strings.Contains(strings.ToLower(it.Creator), s)
+	}
+}
+
+// PlayQueueTrack plays the first track in the device's queue for which match returns true,
+// switching to the queue if the device was playing something else.
+// For a group member, it is the coordinator's queue.
+// It returns the track's 1-based position in the queue.
+func (d *Device) PlayQueueTrack(ctx context.Context, match func(QueueItem) bool) (int, error) {
+	coord, err := d.coordinator(ctx)
+	if err != nil {
+		return 0, err
+	}
+	items, err := coord.Queue(ctx)
+	if err != nil {
+		return 0, err
+	}
+	track := 0
+	for i, it := range items {
+		if match(it) {
+			track = i + 1
+			break
+		}
+	}
+	if track == 0 {
+		return 0, fmt.Errorf("no match among %d queue tracks", len(items))
+	}
+	mi, err := coord.MediaInfo(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if mi.Source() != SourceQueue {
+		if err := coord.selectQueue(ctx); err != nil {
+			return 0, err
+		}
+	}
+	if err := coord.seek(ctx, "TRACK_NR", strconv.Itoa(track)); err != nil {
+		return 0, err
+	}
+	return track, coord.Play(ctx)
+}
+
+// selectQueue makes the device play from its own queue.
+func (d *Device) selectQueue(ctx context.Context) error {
+	if err := d.setAVTransportURI(ctx, "x-rincon-queue:"+d.uid()+"#0", ""); err != nil {
+		return fmt.Errorf("selecting queue: %w", err)
+	}
+	return nil
+}
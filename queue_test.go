@@ -0,0 +1,152 @@
+package sonos
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/dsymonds/sonos/sonostest"
+)
+
+// tracks returns fake tracks with the given URIs.
+func tracks(uris ...string) []sonostest.Track {
+	ts := make([]sonostest.Track, len(uris))
+	for i, uri := range uris {
+		ts[i] = sonostest.Track{URI: uri, Title: "Track " + uri}
+	}
+	return ts
+}
+
+func TestQueuePaging(t *testing.T) {
+	// More tracks than fit in one browse.
+	var want []string
+	for i := range 2*browsePageSize + 5 {
+		want = append(want, fmt.Sprintf("x-file-cifs://nas/%d.mp3", i))
+	}
+	fake := newFake(t, "Kitchen")
+	fake.SetQueue(tracks(want...)...)
+	c := newTestClient(t, nil, fake)
+
+	items, err := device(t, c, fake).Queue(context.Background())
+	if err != nil {
+		t.Fatalf("Queue: %v", err)
+	}
+	var got []string
+	for _, it := range items {
+		got = append(got, it.URI)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Queue returned %d tracks, want %d in order", len(got), len(want))
+	}
+}
+
+// uris returns the URIs of the fake tracks.
+func uris(ts []sonostest.Track) []string {
+	var us []string
+	for _, t := range ts {
+		us = append(us, t.URI)
+	}
+	return us
+}
+
+func TestDedupeQueue(t *testing.T) {
+	tests := []struct {
+		queue   []string
+		want    []string
+		removed int
+	}{
+		{nil, nil, 0},
+		{[]string{"a", "b", "c"}, []string{"a", "b", "c"}, 0},
+		{[]string{"a", "a"}, []string{"a"}, 1},
+		{[]string{"a", "b", "a", "a", "c", "b"}, []string{"a", "b", "c"}, 3},
+		{[]string{"a", "b", "b", "b", "a", "c", "c"}, []string{"a", "b", "c"}, 4},
+	}
+	for _, tc := range tests {
+		fake := newFake(t, "Kitchen")
+		fake.SetQueue(tracks(tc.queue...)...)
+		c := newTestClient(t, nil, fake)
+
+		removed, err := device(t, c, fake).DedupeQueue(context.Background())
+		if err != nil {
+			t.Errorf("DedupeQueue of %q: %v", tc.queue, err)
+			continue
+		}
+		if removed != tc.removed {
+			t.Errorf("DedupeQueue of %q removed %d, want %d", tc.queue, removed, tc.removed)
+		}
+		if got := uris(fake.Queue()); !slices.Equal(got, tc.want) {
+			t.Errorf("DedupeQueue of %q left %q, want %q", tc.queue, got, tc.want)
+		}
+		if tc.removed == 0 && slices.Contains(actions(fake), "RemoveTrackRangeFromQueue") {
+			t.Errorf("DedupeQueue of %q removed tracks unnecessarily", tc.queue)
+		}
+	}
+}
+
+func TestCopyQueue(t *testing.T) {
+	ctx := context.Background()
+	from := newFake(t, "Kitchen")
+	to := newFake(t, "Den")
+	from.SetQueue(tracks("a", "b")...)
+	to.SetQueue(tracks("x", "y")...)
+	c := newTestClient(t, nil, from, to)
+
+	res, err := c.CopyQueue(ctx, device(t, c, from), device(t, c, to), EnqueueOptions{})
+	if err != nil {
+		t.Fatalf("CopyQueue: %v", err)
+	}
+	if want := (EnqueueResult{FirstTrack: 3, TracksAdded: 2, QueueLength: 4}); res != want {
+		t.Errorf("CopyQueue = %+v, want %+v", res, want)
+	}
+	if got, want := uris(to.Queue()), []string{"x", "y", "a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("destination queue is %q, want %q", got, want)
+	}
+
+	if _, err := c.CopyQueue(ctx, device(t, c, from), device(t, c, to), EnqueueOptions{Position: 2}); err != nil {
+		t.Fatalf("CopyQueue at position 2: %v", err)
+	}
+	if got, want := uris(to.Queue()), []string{"x", "a", "b", "y", "a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("destination queue is %q, want %q", got, want)
+	}
+}
+
+func TestShuffleQueue(t *testing.T) {
+	var queue []string
+	for i := range 20 {
+		queue = append(queue, strconv.Itoa(i))
+	}
+	fake := newFake(t, "Kitchen")
+	fake.SetQueue(tracks(queue...)...)
+	c := newTestClient(t, nil, fake)
+
+	if err := device(t, c, fake).ShuffleQueue(context.Background()); err != nil {
+		t.Fatalf("ShuffleQueue: %v", err)
+	}
+	got := uris(fake.Queue())
+	sorted := slices.Clone(got)
+	slices.SortFunc(sorted, func(a, b string) int {
+		x, _ := strconv.Atoi(a)
+		y, _ := strconv.Atoi(b)
+		return x - y
+	})
+	if !slices.Equal(sorted, queue) {
+		t.Errorf("ShuffleQueue left %q, want a permutation of %q", got, queue)
+	}
+	moves := 0
+	for _, call := range fake.Calls() {
+		if call.Action != "ReorderTracksInQueue" {
+			continue
+		}
+		moves++
+		from, _ := strconv.Atoi(call.Args["StartingIndex"])
+		before, _ := strconv.Atoi(call.Args["InsertBefore"])
+		if from < 1 || from > len(queue) || before < 1 || before >= from {
+			t.Errorf("ReorderTracksInQueue moved track %d before %d", from, before)
+		}
+	}
+	if moves >= len(queue) {
+		t.Errorf("ShuffleQueue made %d moves, want fewer than %d", moves, len(queue))
+	}
+}
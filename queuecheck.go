@@ -0,0 +1,110 @@
+package sonos
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// QueueProblem is a queue entry that is unlikely to play,
+// and which the device would silently skip.
+type QueueProblem struct {
+	Track  int // 1-based position in the queue
+	Item   QueueItem
+	Reason string
+}
+
+// CheckQueue looks for entries in the device's queue that refer to music shares
+// that are no longer part of the library, such as after a share is removed.
+func (d *Device) CheckQueue(ctx context.Context) ([]QueueProblem, error) {
+	shares, err := d.musicShares(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := d.Queue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var probs []QueueProblem
+	for i, it := range items {
+		path, ok := strings.CutPrefix(it.URI, "x-file-cifs:")
+		if !ok {
+			continue
+		}
+		if p, err := url.PathUnescape(path); err == nil {
+			path = p
+		}
+		path = strings.ToLower(path)
+		found := false
+		for _, share := range shares {
+			if strings.HasPrefix(path, share+"/") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			probs = append(probs, QueueProblem{Track: i + 1, Item: it, Reason: "not in a library share"})
+		}
+	}
+	return probs, nil
+}
+
+// musicShares returns the library's shares, lowercased, in the form "//server/share".
+func (d *Device) musicShares(ctx context.Context) ([]string, error) {
+	var shares []string
+	for it, err := range d.BrowseIter(ctx, "S:") {
+		if err != nil {
+			return nil, fmt.Errorf("listing music shares: %w", err)
+		}
+		share := strings.TrimSuffix(strings.TrimPrefix(it.ID, "S:"), "/")
+		shares = append(shares, strings.ToLower(share))
+	}
+	return shares, nil
+}
+
+// RepairQueue fixes the problems found by CheckQueue.
+// If resolve is set, each entry is replaced by a library track with the same
+// title and creator, if there is one; other entries are removed.
+// It returns how many entries were replaced and how many removed.
+func (d *Device) RepairQueue(ctx context.Context, probs []QueueProblem, resolve bool) (replaced, removed int, err error) {
+	// Work from the end, so earlier positions stay valid.
+	for i := len(probs) - 1; i >= 0; i-- {
+		p := probs[i]
+		var repl *BrowseItem
+		if resolve {
+			if repl, err = d.findLibraryTrack(ctx, p.Item.Title, p.Item.Creator); err != nil {
+				return replaced, removed, err
+			}
+		}
+		if err := d.removeTrackRange(ctx, p.Track, 1); err != nil {
+			return replaced, removed, err
+		}
+		if repl == nil {
+			removed++
+			continue
+		}
+		if _, err := d.addURIToQueue(ctx, repl.URI, repl.Metadata, EnqueueOptions{Position: p.Track}); err != nil {
+			return replaced, removed, fmt.Errorf("replacing %q: %w", p.Item.Title, err)
+		}
+		replaced++
+	}
+	return replaced, removed, nil
+}
+
+// findLibraryTrack returns a track in the library with the given title and creator, or nil.
+func (d *Device) findLibraryTrack(ctx context.Context, title, creator string) (*BrowseItem, error) {
+	if title == "" {
+		return nil, nil
+	}
+	// Browsing "A:TRACKS:" with a term searches the tracks' titles.
+	for it, err := range d.BrowseIter(ctx, "A:TRACKS:"+url.PathEscape(title)) {
+		if err != nil {
+			return nil, fmt.Errorf("searching library for %q: %w", title, err)
+		}
+		if !it.Container && strings.EqualFold(it.Title, title) && strings.EqualFold(it.Creator, creator) {
+			return &it, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,66 @@
+package sonos
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter is a token bucket limiting the rate of requests to one device.
+type limiter struct {
+	every time.Duration // time to earn a token
+	burst int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(every time.Duration, burst int) *limiter {
+	return &limiter{
+		every:  every,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a request may be made, or until ctx is done.
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += float64(now.Sub(l.last)) / float64(l.every)
+	l.tokens = min(l.tokens, float64(l.burst))
+	l.last = now
+	// Take the token now, even if it has not been earned yet,
+	// so that concurrent waiters queue up behind each other.
+	l.tokens--
+	delay := time.Duration(-l.tokens * float64(l.every))
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	if err := sleepCtx(ctx, delay); err != nil {
+		// Give the token back.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// limiter returns the rate limiter for the device, or nil if it is not rate limited.
+func (d *Device) limiter() *limiter {
+	if d.c == nil || d.c.opts.rateEvery <= 0 {
+		return nil
+	}
+	d.c.mu.Lock()
+	defer d.c.mu.Unlock()
+	l, ok := d.c.limiters[d.dev.UDN]
+	if !ok {
+		l = newLimiter(d.c.opts.rateEvery, max(d.c.opts.rateBurst, 1))
+		d.c.limiters[d.dev.UDN] = l
+	}
+	return l
+}
@@ -0,0 +1,432 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/huin/goupnp/dcps/av1"
+)
+
+// Volume returns the device's volume, in range [0,100].
+// With WithGroupVolume, the volume of a group coordinator's whole group is returned.
+func (d *Device) Volume(ctx context.Context) (int, error) {
+	if d.groupVolumes() {
+		vol, err := d.GroupVolume(ctx)
+		if ErrorCode(err) != ErrCodeNotCoordinator {
+			return vol, err
+		}
+	}
+	var resp struct {
+		CurrentVolume string // ui2
+	}
+	err := d.soap(ctx, av1.URN_RenderingControl_1, "GetVolume", struct {
+		InstanceID string
+		Channel    string
+	}{
+		InstanceID: "0",
+		Channel:    "Master",
+	}, &resp)
+	if err != nil {
+		return 0, fmt.Errorf("getting volume: %w", err)
+	}
+	vol, err := strconv.Atoi(resp.CurrentVolume)
+	if err != nil {
+		return 0, fmt.Errorf("parsing volume %q: %w", resp.CurrentVolume, err)
+	}
+	return vol, nil
+}
+
+// Channel is an audio channel of a device.
+type Channel string
+
+const (
+	MasterChannel Channel = "Master"
+	LeftChannel   Channel = "LF"
+	RightChannel  Channel = "RF"
+)
+
+// ChannelVolume returns the volume of one of the device's channels, in range [0,100].
+// The left and right channels' volumes are relative to the master volume,
+// and are how balance is set; for a bonded pair, they are the volumes of its members.
+func (d *Device) ChannelVolume(ctx context.Context, ch Channel) (int, error) {
+	var resp struct {
+		CurrentVolume string // ui2
+	}
+	err := d.soap(ctx, av1.URN_RenderingControl_1, "GetVolume", struct {
+		InstanceID string
+		Channel    string
+	}{
+		InstanceID: "0",
+		Channel:    string(ch),
+	}, &resp)
+	if err != nil {
+		return 0, fmt.Errorf("getting %s volume: %w", ch, err)
+	}
+	vol, err := strconv.Atoi(resp.CurrentVolume)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s volume %q: %w", ch, resp.CurrentVolume, err)
+	}
+	return vol, nil
+}
+
+// SetChannelVolume sets the volume of one of the device's channels, in range [0,100].
+// Only the master volume is subject to any volume ceiling.
+func (d *Device) SetChannelVolume(ctx context.Context, ch Channel, volume int) error {
+	if ch == MasterChannel {
+		volume = d.clampVolume(volume)
+	}
+	err := d.soap(ctx, av1.URN_RenderingControl_1, "SetVolume", struct {
+		InstanceID    string
+		Channel       string
+		DesiredVolume string
+	}{
+		InstanceID:    "0",
+		Channel:       string(ch),
+		DesiredVolume: strconv.Itoa(max(0, min(volume, 100))),
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting %s volume: %w", ch, err)
+	}
+	return nil
+}
+
+// Mute reports whether the device is muted.
+func (d *Device) Mute(ctx context.Context) (bool, error) {
+	var resp struct {
+		CurrentMute string // bool
+	}
+	err := d.soap(ctx, av1.URN_RenderingControl_1, "GetMute", struct {
+		InstanceID string
+		Channel    string
+	}{
+		InstanceID: "0",
+		Channel:    "Master",
+	}, &resp)
+	if err != nil {
+		return false, fmt.Errorf("getting mute: %w", err)
+	}
+	return resp.CurrentMute == "1", nil
+}
+
+// SetMute mutes or unmutes the device.
+func (d *Device) SetMute(ctx context.Context, mute bool) error {
+	err := d.soap(ctx, av1.URN_RenderingControl_1, "SetMute", struct {
+		InstanceID  string
+		Channel     string
+		DesiredMute string
+	}{
+		InstanceID:  "0",
+		Channel:     "Master",
+		DesiredMute: boolString(mute),
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting mute: %w", err)
+	}
+	return nil
+}
+
+// OutputFixed reports whether the device's line-out is at a fixed level,
+// leaving volume control to an external amplifier.
+func (d *Device) OutputFixed(ctx context.Context) (bool, error) {
+	var resp struct {
+		CurrentFixed string // bool
+	}
+	err := d.soap(ctx, av1.URN_RenderingControl_1, "GetOutputFixed", struct {
+		InstanceID string
+	}{InstanceID: "0"}, &resp)
+	if err != nil {
+		return false, fmt.Errorf("getting output fixed: %w", err)
+	}
+	return resp.CurrentFixed == "1", nil
+}
+
+// SetOutputFixed sets whether the device's line-out is at a fixed level.
+// Only some devices (e.g. Port and Connect) support this; see SupportsOutputFixed.
+func (d *Device) SetOutputFixed(ctx context.Context, fixed bool) error {
+	err := d.soap(ctx, av1.URN_RenderingControl_1, "SetOutputFixed", struct {
+		InstanceID   string
+		DesiredFixed string
+	}{
+		InstanceID:   "0",
+		DesiredFixed: boolString(fixed),
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting output fixed: %w", err)
+	}
+	return nil
+}
+
+// SupportsOutputFixed reports whether the device's line-out can be set to a fixed level.
+func (d *Device) SupportsOutputFixed(ctx context.Context) (bool, error) {
+	var resp struct {
+		CurrentSupportsFixed string // bool
+	}
+	err := d.soap(ctx, av1.URN_RenderingControl_1, "GetSupportsOutputFixed", struct {
+		InstanceID string
+	}{InstanceID: "0"}, &resp)
+	if err != nil {
+		return false, fmt.Errorf("getting support for output fixed: %w", err)
+	}
+	return resp.CurrentSupportsFixed == "1", nil
+}
+
+// OutputSettings are a device's settings for its outputs,
+// such as those configured when installing a Port or Amp.
+// Settings that are nil are left unchanged by ApplyOutputSettings,
+// and are not reported by OutputSettings for devices that lack them.
+type OutputSettings struct {
+	Fixed        *bool // line-out at a fixed level rather than following the volume; e.g. Port
+	SubCrossover *int  // in Hz, in range [50,110]; Amp only
+}
+
+// OutputSettings returns the device's output settings.
+func (d *Device) OutputSettings(ctx context.Context) (OutputSettings, error) {
+	var s OutputSettings
+	var uerr *UPnPError
+	supported, err := d.SupportsOutputFixed(ctx)
+	if err != nil && !errors.As(err, &uerr) {
+		return OutputSettings{}, err
+	}
+	if supported {
+		fixed, err := d.OutputFixed(ctx)
+		if err != nil {
+			return OutputSettings{}, err
+		}
+		s.Fixed = &fixed
+	}
+	v, err := d.EQ(ctx, SubCrossoverEQ)
+	if err == nil {
+		s.SubCrossover = &v
+	} else if !errors.As(err, &uerr) {
+		return OutputSettings{}, err
+	}
+	return s, nil
+}
+
+// ApplyOutputSettings sets the device's output settings.
+func (d *Device) ApplyOutputSettings(ctx context.Context, s OutputSettings) error {
+	if s.Fixed != nil {
+		if err := d.SetOutputFixed(ctx, *s.Fixed); err != nil {
+			return err
+		}
+	}
+	if s.SubCrossover != nil {
+		if err := d.SetEQ(ctx, SubCrossoverEQ, max(50, min(*s.SubCrossover, 110))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupVolumes reports whether the device's Volume and SetVolume apply to its whole group.
+func (d *Device) groupVolumes() bool {
+	return d.c != nil && d.c.opts.groupVolume
+}
+
+// LimitVolume sets a ceiling on the volumes that the Client will set for the device,
+// overriding any set with WithMaxVolume. A negative limit removes the device's ceiling.
+func (c *Client) LimitVolume(d *Device, limit int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if limit < 0 {
+		delete(c.maxVolumes, d.dev.UDN)
+		return
+	}
+	c.maxVolumes[d.dev.UDN] = limit
+}
+
+// clampVolume limits a volume to the valid range,
+// and to any ceiling the Client has for the device.
+func (d *Device) clampVolume(volume int) int {
+	ceiling := 100
+	if d.c != nil {
+		d.c.mu.Lock()
+		ceiling = d.c.ceiling(d.dev.UDN)
+		d.c.mu.Unlock()
+	}
+	return max(0, min(volume, ceiling, 100))
+}
+
+// ceiling returns the volume ceiling for the device with the given UDN.
+// c.mu must be held.
+func (c *Client) ceiling(udn string) int {
+	if limit, ok := c.maxVolumes[udn]; ok {
+		return limit
+	}
+	if c.opts.maxVolume > 0 {
+		return c.opts.maxVolume
+	}
+	return 100
+}
+
+// clampGroupVolume limits a group volume to the valid range,
+// and to the strictest ceiling the Client has for any of the group's members.
+// The members' own volumes are scaled around the group volume,
+// so a member louder than the rest may still end up above its ceiling.
+func (d *Device) clampGroupVolume(ctx context.Context, volume int) (int, error) {
+	ceiling := 100
+	if d.c != nil {
+		d.c.mu.Lock()
+		limited := len(d.c.maxVolumes) > 0 || d.c.opts.maxVolume > 0
+		d.c.mu.Unlock()
+		if limited {
+			_, group, err := d.topologyMember(ctx)
+			if err != nil {
+				return 0, err
+			}
+			d.c.mu.Lock()
+			for _, m := range group.Members {
+				ceiling = min(ceiling, d.c.ceiling("uuid:"+m.UUID))
+			}
+			d.c.mu.Unlock()
+		}
+	}
+	return max(0, min(volume, ceiling, 100)), nil
+}
+
+// minFadeStep is the shortest time between volume changes when fading.
+// Devices cope poorly with being sent changes much faster than this.
+const minFadeStep = 100 * time.Millisecond
+
+// FadeVolume changes the device's volume to the target in steps spread over the given duration.
+// Unlike RampToVolume, the timing is under the caller's control,
+// and the fade stops early if the context is cancelled.
+func (d *Device) FadeVolume(ctx context.Context, target int, over time.Duration) error {
+	target = d.clampVolume(target)
+	vol, err := d.Volume(ctx)
+	if err != nil {
+		return err
+	}
+	steps := target - vol
+	if steps < 0 {
+		steps = -steps
+	}
+	if steps == 0 {
+		return nil
+	}
+	interval := over / time.Duration(steps)
+	stride := 1
+	if interval < minFadeStep {
+		// Take bigger steps, less often.
+		interval = minFadeStep
+		stride = int(time.Duration(steps) * minFadeStep / max(over, 1))
+		stride = min(max(stride, 1), steps)
+	}
+	if target < vol {
+		stride = -stride
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for vol != target {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+		vol += stride
+		if (stride > 0 && vol > target) || (stride < 0 && vol < target) {
+			vol = target
+		}
+		if err := d.SetVolume(ctx, vol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GroupVolume returns the volume of the group that the device coordinates, in range [0,100].
+func (d *Device) GroupVolume(ctx context.Context) (int, error) {
+	var resp struct {
+		CurrentVolume string // ui2
+	}
+	err := d.soap(ctx, GroupRenderingControlService, "GetGroupVolume", struct {
+		InstanceID string
+	}{InstanceID: "0"}, &resp)
+	if err != nil {
+		return 0, fmt.Errorf("getting group volume: %w", err)
+	}
+	vol, err := strconv.Atoi(resp.CurrentVolume)
+	if err != nil {
+		return 0, fmt.Errorf("parsing group volume %q: %w", resp.CurrentVolume, err)
+	}
+	return vol, nil
+}
+
+// SetGroupVolume sets the volume of the group that the device coordinates.
+// The members' volumes are scaled to keep their balance.
+// The volume is limited to the lowest ceiling set for any member (see LimitVolume).
+func (d *Device) SetGroupVolume(ctx context.Context, volume int) error {
+	volume, err := d.clampGroupVolume(ctx, volume)
+	if err != nil {
+		return err
+	}
+	if err := d.snapshotGroupVolume(ctx); err != nil {
+		return err
+	}
+	err = d.soap(ctx, GroupRenderingControlService, "SetGroupVolume", struct {
+		InstanceID    string
+		DesiredVolume string
+	}{
+		InstanceID:    "0",
+		DesiredVolume: strconv.Itoa(volume),
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting group volume: %w", err)
+	}
+	return nil
+}
+
+// AdjustGroupVolume changes the volume of the group that the device coordinates by delta,
+// keeping the balance between the members' volumes, as the Sonos app does.
+// Increases are limited as for SetGroupVolume. It returns the new group volume.
+func (d *Device) AdjustGroupVolume(ctx context.Context, delta int) (int, error) {
+	if err := d.snapshotGroupVolume(ctx); err != nil {
+		return 0, err
+	}
+	if delta > 0 {
+		// Respect any volume ceiling.
+		vol, err := d.GroupVolume(ctx)
+		if err != nil {
+			return 0, err
+		}
+		target, err := d.clampGroupVolume(ctx, vol+delta)
+		if err != nil {
+			return 0, err
+		}
+		delta = max(0, target-vol)
+	}
+	var resp struct {
+		NewVolume string // ui2
+	}
+	err := d.soap(ctx, GroupRenderingControlService, "SetRelativeGroupVolume", struct {
+		InstanceID string
+		Adjustment string // i4
+	}{
+		InstanceID: "0",
+		Adjustment: strconv.Itoa(delta),
+	}, &resp)
+	if err != nil {
+		return 0, fmt.Errorf("adjusting group volume: %w", err)
+	}
+	vol, err := strconv.Atoi(resp.NewVolume)
+	if err != nil {
+		return 0, fmt.Errorf("parsing new group volume %q: %w", resp.NewVolume, err)
+	}
+	return vol, nil
+}
+
+// snapshotGroupVolume records the ratios between the group members' volumes,
+// which subsequent group volume changes preserve.
+func (d *Device) snapshotGroupVolume(ctx context.Context) error {
+	err := d.soap(ctx, GroupRenderingControlService, "SnapshotGroupVolume", struct {
+		InstanceID string
+	}{InstanceID: "0"}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("snapshotting group volume: %w", err)
+	}
+	return nil
+}
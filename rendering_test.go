@@ -0,0 +1,107 @@
+package sonos
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/dsymonds/sonos/sonostest"
+)
+
+func TestVolumeClamp(t *testing.T) {
+	ctx := context.Background()
+	fake := newFake(t, "Kitchen")
+	c := newTestClient(t, []Option{WithMaxVolume(50)}, fake)
+	d := device(t, c, fake)
+
+	set := func(vol int, want string) {
+		t.Helper()
+		if err := d.SetVolume(ctx, vol); err != nil {
+			t.Fatalf("SetVolume(%d): %v", vol, err)
+		}
+		if got := fake.State("Volume"); got != want {
+			t.Errorf("after SetVolume(%d), volume is %s, want %s", vol, got, want)
+		}
+	}
+	set(40, "40")
+	set(80, "50")
+	set(-5, "0")
+
+	c.LimitVolume(d, 30)
+	set(40, "30")
+	c.LimitVolume(d, 70) // overrides WithMaxVolume
+	set(90, "70")
+	c.LimitVolume(d, -1)
+	set(90, "50")
+
+	if err := d.SetAutoplayVolume(ctx, 90, true); err != nil {
+		t.Fatalf("SetAutoplayVolume: %v", err)
+	}
+	var sent string
+	for _, call := range fake.Calls() {
+		if call.Action == "SetAutoplayVolume" {
+			sent = call.Args["Volume"]
+		}
+	}
+	if sent != "50" {
+		t.Errorf("SetAutoplayVolume(90) sent volume %q, want 50", sent)
+	}
+}
+
+func TestVolumeClampRange(t *testing.T) {
+	fake := newFake(t, "Kitchen")
+	c := newTestClient(t, nil, fake)
+	d := device(t, c, fake)
+	if err := d.SetVolume(context.Background(), 101); err != nil {
+		t.Fatalf("SetVolume: %v", err)
+	}
+	if got := fake.State("Volume"); got != "100" {
+		t.Errorf("volume is %s, want 100", got)
+	}
+}
+
+func TestGroupVolumeClamp(t *testing.T) {
+	ctx := context.Background()
+	lead := newFake(t, "Living Room")
+	member := newFake(t, "Kitchen")
+	sonostest.Group(lead, member)
+	c := newTestClient(t, nil, lead, member)
+	d := device(t, c, lead)
+
+	// Without any ceilings, the topology is not needed.
+	if err := d.SetGroupVolume(ctx, 60); err != nil {
+		t.Fatalf("SetGroupVolume: %v", err)
+	}
+	if got := lead.State("Volume"); got != "60" {
+		t.Errorf("group volume is %s, want 60", got)
+	}
+	if slices.Contains(actions(lead), "GetZoneGroupState") {
+		t.Errorf("SetGroupVolume fetched the topology without any ceilings set")
+	}
+
+	// The member's ceiling limits the group.
+	c.LimitVolume(device(t, c, member), 25)
+	if err := d.SetGroupVolume(ctx, 60); err != nil {
+		t.Fatalf("SetGroupVolume: %v", err)
+	}
+	if got := lead.State("Volume"); got != "25" {
+		t.Errorf("group volume is %s, want 25", got)
+	}
+	if err := d.SetVolume(ctx, 20); err != nil {
+		t.Fatalf("SetVolume: %v", err)
+	}
+	vol, err := d.AdjustGroupVolume(ctx, 10)
+	if err != nil {
+		t.Fatalf("AdjustGroupVolume: %v", err)
+	}
+	if vol != 25 {
+		t.Errorf("AdjustGroupVolume = %d, want 25", vol)
+	}
+	// Decreases are not limited.
+	if vol, err = d.AdjustGroupVolume(ctx, -10); err != nil {
+		t.Fatalf("AdjustGroupVolume: %v", err)
+	}
+	if vol != 15 {
+		t.Errorf("AdjustGroupVolume = %d, want 15", vol)
+	}
+}
@@ -0,0 +1,323 @@
+package sonos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ZoneRestorePoint is the state of a zone as saved by a Restorer.
+type ZoneRestorePoint struct {
+	Zone        string
+	UUID        string
+	BootSeq     int    // the device's boot sequence number when the snapshot was taken
+	Coordinator string // UUID of the coordinator of the zone's group, or empty if it is the coordinator
+	Volume      int    // the device's own volume, even if group volume is in use
+	Mute        bool
+
+	// Snapshot is what the zone's group was playing, and its queue and play mode.
+	// It is for coordinators only.
+	Snapshot *Snapshot
+
+	Time time.Time
+}
+
+// A Restorer keeps snapshots of each zone, and puts zones back the way they were
+// after their players restart, such as for overnight software updates:
+// rejoining groups, resetting volumes, and for coordinators that have lost
+// what they were playing, restoring it with Device.Restore.
+// Playback is not restarted.
+// Restarts are noticed from the boot sequence numbers in the household's topology,
+// which the Restorer follows with Client.Watch.
+type Restorer struct {
+	Client *Client
+
+	// Path, if set, is a file in which to keep the snapshots as JSON,
+	// so that they survive restarts of the caller too.
+	Path string
+
+	Interval time.Duration // how often to take snapshots; default 5m
+
+	// OnRestore, if set, is called after each attempt to restore a zone.
+	// Failed attempts are retried at the next check.
+	OnRestore func(zone string, err error)
+
+	mu     sync.Mutex
+	points map[string]ZoneRestorePoint // by UUID
+}
+
+// Run takes snapshots and restores zones until ctx is done.
+// It returns an error only if the snapshots in Path cannot be loaded.
+func (r *Restorer) Run(ctx context.Context) error {
+	if err := r.load(); err != nil {
+		return err
+	}
+	events := r.Client.Watch(ctx)
+	tick := time.NewTicker(or(r.Interval, 5*time.Minute))
+	defer tick.Stop()
+
+	r.checkpoint(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			for range events {
+			}
+			return nil
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if te, ok := ev.(*TopologyEvent); ok {
+				r.restore(ctx, te.Groups)
+			}
+		case <-tick.C:
+			r.checkpoint(ctx)
+		}
+	}
+}
+
+// RestorePoints returns the latest snapshots, ordered by zone name.
+func (r *Restorer) RestorePoints() []ZoneRestorePoint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var pts []ZoneRestorePoint
+	for _, p := range r.points {
+		pts = append(pts, p)
+	}
+	sort.Slice(pts, func(i, j int) bool { return pts[i].Zone < pts[j].Zone })
+	return pts
+}
+
+// checkpoint restores any zones that need it, and then snapshots the rest.
+func (r *Restorer) checkpoint(ctx context.Context) {
+	groups, err := r.Client.zoneGroups(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.Client.logger.WarnContext(ctx, "Polling zone groups", "err", err)
+		}
+		return
+	}
+	r.restore(ctx, groups)
+	if err := r.snapshot(ctx, groups); err != nil && ctx.Err() == nil {
+		r.Client.logger.WarnContext(ctx, "Taking zone snapshots", "err", err)
+	}
+}
+
+// restarted returns the restore points of the zones whose devices have restarted
+// since their snapshots, coordinators first, along with the zones' current coordinators.
+func (r *Restorer) restarted(groups []ZoneGroup) ([]ZoneRestorePoint, map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var pts []ZoneRestorePoint
+	coords := make(map[string]string)
+	for _, g := range groups {
+		for _, m := range g.Members {
+			coords[m.UUID] = g.Coordinator
+			p, ok := r.points[m.UUID]
+			if ok && !m.Invisible && m.BootSeq != 0 && m.BootSeq != p.BootSeq {
+				p.BootSeq = m.BootSeq
+				pts = append(pts, p)
+			}
+		}
+	}
+	sort.SliceStable(pts, func(i, j int) bool { return pts[i].Coordinator == "" && pts[j].Coordinator != "" })
+	return pts, coords
+}
+
+// restore puts back the zones that have restarted.
+func (r *Restorer) restore(ctx context.Context, groups []ZoneGroup) {
+	pts, coords := r.restarted(groups)
+	if len(pts) == 0 {
+		return
+	}
+	byUUID := make(map[string]*Device)
+	for _, d := range r.Client.Devices() {
+		byUUID[d.uid()] = d
+	}
+	for _, p := range pts {
+		d, ok := byUUID[p.UUID]
+		if !ok {
+			continue // not rediscovered yet
+		}
+		err := r.restoreZone(ctx, d, p, coords[p.UUID], byUUID)
+		if err != nil {
+			err = fmt.Errorf("restoring %s: %w", p.Zone, err)
+			r.Client.logger.WarnContext(ctx, "Restoring zone", "zone", p.Zone, "err", err)
+		} else {
+			r.mu.Lock()
+			r.points[p.UUID] = p // with the new boot sequence number
+			r.mu.Unlock()
+		}
+		if r.OnRestore != nil {
+			r.OnRestore(p.Zone, err)
+		}
+	}
+	if err := r.save(); err != nil {
+		r.Client.logger.WarnContext(ctx, "Saving zone snapshots", "err", err)
+	}
+}
+
+func (r *Restorer) restoreZone(ctx context.Context, d *Device, p ZoneRestorePoint, coord string, byUUID map[string]*Device) error {
+	if p.Coordinator == "" {
+		if err := restorePlayback(ctx, d, p); err != nil {
+			return err
+		}
+	}
+	// Set the device's own volume, even if group volume is in use.
+	if err := d.SetChannelVolume(ctx, MasterChannel, p.Volume); err != nil {
+		return err
+	}
+	if err := d.SetMute(ctx, p.Mute); err != nil {
+		return err
+	}
+
+	if p.Coordinator == "" || coord == p.Coordinator {
+		return nil // not grouped, or already rejoined
+	}
+	lead, ok := byUUID[p.Coordinator]
+	if !ok {
+		return fmt.Errorf("coordinator %s not found", p.Coordinator)
+	}
+	return d.Join(ctx, lead)
+}
+
+// restorePlayback restores a coordinator's snapshot if it has lost what it was playing.
+func restorePlayback(ctx context.Context, d *Device, p ZoneRestorePoint) error {
+	if p.Snapshot == nil || p.Snapshot.Media.Source() == SourceNone {
+		return nil
+	}
+	media, err := d.MediaInfo(ctx)
+	if err != nil {
+		return err
+	}
+	if media.Source() != SourceNone {
+		return nil // it still has something to play
+	}
+	snap := *p.Snapshot
+	snap.State = Stopped // playback is not restarted
+	return d.Restore(ctx, &snap)
+}
+
+// snapshot records the state of each zone whose restore point is up to date.
+func (r *Restorer) snapshot(ctx context.Context, groups []ZoneGroup) error {
+	byUUID := make(map[string]*Device)
+	for _, d := range r.Client.Devices() {
+		byUUID[d.uid()] = d
+	}
+	r.mu.Lock()
+	var pts []ZoneRestorePoint
+	for _, g := range groups {
+		for _, m := range g.Members {
+			if m.Invisible || byUUID[m.UUID] == nil {
+				continue
+			}
+			if old, ok := r.points[m.UUID]; ok && m.BootSeq != 0 && old.BootSeq != m.BootSeq {
+				continue // awaiting restoration
+			}
+			p := ZoneRestorePoint{Zone: m.ZoneName, UUID: m.UUID, BootSeq: m.BootSeq}
+			if m.UUID != g.Coordinator {
+				p.Coordinator = g.Coordinator
+			}
+			pts = append(pts, p)
+		}
+	}
+	r.mu.Unlock()
+
+	errs := make([]error, len(pts))
+	var wg sync.WaitGroup
+	for i := range pts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = snapshotZone(ctx, byUUID[pts[i].UUID], &pts[i])
+		}(i)
+	}
+	wg.Wait()
+
+	r.mu.Lock()
+	if r.points == nil {
+		r.points = make(map[string]ZoneRestorePoint)
+	}
+	for i, p := range pts {
+		if errs[i] == nil {
+			r.points[p.UUID] = p
+		}
+	}
+	r.mu.Unlock()
+	return errors.Join(append(errs, r.save())...)
+}
+
+func snapshotZone(ctx context.Context, d *Device, p *ZoneRestorePoint) error {
+	var err error
+	if p.Volume, err = d.ChannelVolume(ctx, MasterChannel); err != nil {
+		return fmt.Errorf("%s: %w", p.Zone, err)
+	}
+	if p.Mute, err = d.Mute(ctx); err != nil {
+		return fmt.Errorf("%s: %w", p.Zone, err)
+	}
+	if p.Coordinator == "" {
+		if p.Snapshot, err = d.Snapshot(ctx); err != nil {
+			return fmt.Errorf("%s: %w", p.Zone, err)
+		}
+	}
+	p.Time = time.Now()
+	return nil
+}
+
+// load reads the snapshots from r.Path, if it is set and exists.
+func (r *Restorer) load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.points = make(map[string]ZoneRestorePoint)
+	if r.Path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(r.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("loading zone snapshots: %w", err)
+	}
+	var pts []ZoneRestorePoint
+	if err := json.Unmarshal(data, &pts); err != nil {
+		return fmt.Errorf("parsing zone snapshots: %w", err)
+	}
+	for _, p := range pts {
+		r.points[p.UUID] = p
+	}
+	return nil
+}
+
+// save writes the snapshots to r.Path, if it is set.
+func (r *Restorer) save() error {
+	if r.Path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(r.RestorePoints(), "", "\t")
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first, so the old snapshots survive a failed write.
+	tmp, err := os.CreateTemp(filepath.Dir(r.Path), filepath.Base(r.Path)+".*")
+	if err != nil {
+		return fmt.Errorf("saving zone snapshots: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("saving zone snapshots: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("saving zone snapshots: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.Path); err != nil {
+		return fmt.Errorf("saving zone snapshots: %w", err)
+	}
+	return nil
+}
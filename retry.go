@@ -0,0 +1,94 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how SOAP actions are retried after transient failures,
+// such as players dropping connections during group transitions.
+//
+// By default, an action is retried only if it failed without reaching the device
+// (see DefaultRetryable), except that actions which are safe to repeat,
+// such as getters and those that set absolute values like SetVolume,
+// are also retried after other transient failures (see IdempotentRetryable).
+// Actions such as AddURIToQueue, RemoveTrackRangeFromQueue or SetRelativeGroupVolume
+// are not retried once the device may have performed them,
+// since doing them twice would add, remove or adjust twice.
+type RetryPolicy struct {
+	Attempts int           // total attempts, including the first; values below 2 mean no retries
+	Backoff  time.Duration // delay before the first retry, doubled for each subsequent one
+
+	// Retryable, if set, reports whether an error is transient, replacing the default rules.
+	// It is used for every action, including those that are not safe to repeat.
+	Retryable func(error) bool
+}
+
+func (rp RetryPolicy) shouldRetry(action string, attempt int, err error) bool {
+	if attempt >= rp.Attempts {
+		return false
+	}
+	if rp.Retryable != nil {
+		return rp.Retryable(err)
+	}
+	if idempotent(action) {
+		return IdempotentRetryable(err)
+	}
+	return DefaultRetryable(err)
+}
+
+func (rp RetryPolicy) backoff(attempt int) time.Duration {
+	return rp.Backoff << (attempt - 1)
+}
+
+// DefaultRetryable reports whether err is a transient failure that happened
+// before the request reached the device, such as a refused connection,
+// so that retrying any action is safe.
+// Errors reported by the device (UPnPError) and context errors are not transient.
+func DefaultRetryable(err error) bool {
+	// goupnp does not wrap its underlying errors, so this has to inspect the messages.
+	return IdempotentRetryable(err) && strings.Contains(err.Error(), "dial ")
+}
+
+// IdempotentRetryable reports whether err is a transient failure:
+// either the HTTP request failed, or the device returned an HTTP error without a SOAP fault.
+// The device may have performed the action regardless,
+// so this is only suitable for actions that are safe to repeat.
+// Errors reported by the device (UPnPError) and context errors are not transient.
+func IdempotentRetryable(err error) bool {
+	var ue *UPnPError
+	if errors.As(err, &ue) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "error performing SOAP HTTP request") ||
+		strings.Contains(msg, "SOAP request got HTTP") ||
+		strings.Contains(msg, "error decoding response body")
+}
+
+// idempotent reports whether doing an action twice has the same effect as doing it once:
+// getters, and actions that set absolute values.
+func idempotent(action string) bool {
+	switch action {
+	case "Browse", "Search", "Play", "Pause", "Stop", "Seek", "ConfigureSleepTimer":
+		return true
+	}
+	if strings.HasPrefix(action, "SetRelative") {
+		return false
+	}
+	return strings.HasPrefix(action, "Get") || strings.HasPrefix(action, "Set")
+}
+
+// sleepCtx sleeps for d, or until ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
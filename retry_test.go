@@ -0,0 +1,117 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestIdempotent(t *testing.T) {
+	tests := []struct {
+		action string
+		want   bool
+	}{
+		{"GetVolume", true},
+		{"SetVolume", true},
+		{"SetAVTransportURI", true},
+		{"Play", true},
+		{"Seek", true},
+		{"ConfigureSleepTimer", true},
+		{"Browse", true},
+		{"SetRelativeVolume", false},
+		{"SetRelativeGroupVolume", false},
+		{"AddURIToQueue", false},
+		{"RemoveTrackRangeFromQueue", false},
+		{"ReorderTracksInQueue", false},
+		{"Next", false},
+	}
+	for _, tc := range tests {
+		if got := idempotent(tc.action); got != tc.want {
+			t.Errorf("idempotent(%q) = %t, want %t", tc.action, got, tc.want)
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err                 error
+		dflt, forIdempotent bool
+	}{
+		{errors.New("goupnp: error performing SOAP HTTP request: dial tcp 10.0.0.2:1400: connect: connection refused"), true, true},
+		{errors.New("goupnp: error performing SOAP HTTP request: read tcp: connection reset by peer"), false, true},
+		{errors.New("goupnp: SOAP request got HTTP 503 Service Unavailable"), false, true},
+		{errors.New("goupnp: error decoding response body: EOF"), false, true},
+		{fmt.Errorf("playing: %w", &UPnPError{Action: "Play", Code: 701}), false, false},
+		{fmt.Errorf("getting volume: %w", context.DeadlineExceeded), false, false},
+		{context.Canceled, false, false},
+		{errors.New("parsing volume"), false, false},
+	}
+	for _, tc := range tests {
+		if got := DefaultRetryable(tc.err); got != tc.dflt {
+			t.Errorf("DefaultRetryable(%v) = %t, want %t", tc.err, got, tc.dflt)
+		}
+		if got := IdempotentRetryable(tc.err); got != tc.forIdempotent {
+			t.Errorf("IdempotentRetryable(%v) = %t, want %t", tc.err, got, tc.forIdempotent)
+		}
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	reset := errors.New("goupnp: error performing SOAP HTTP request: read tcp: connection reset by peer")
+	rp := RetryPolicy{Attempts: 3}
+	if !rp.shouldRetry("GetVolume", 1, reset) {
+		t.Errorf("GetVolume not retried after a reset connection")
+	}
+	if rp.shouldRetry("GetVolume", 3, reset) {
+		t.Errorf("GetVolume retried after the last attempt")
+	}
+	if rp.shouldRetry("AddURIToQueue", 1, reset) {
+		t.Errorf("AddURIToQueue retried after a reset connection")
+	}
+	rp.Retryable = func(error) bool { return true }
+	if !rp.shouldRetry("AddURIToQueue", 1, reset) {
+		t.Errorf("AddURIToQueue not retried with a custom Retryable")
+	}
+}
+
+func TestRetryDevice(t *testing.T) {
+	// The fake answers with an HTTP error, which may follow a performed action.
+	ctx := context.Background()
+	fake := newFake(t, "Kitchen")
+	failures := 0
+	fake.Handle("GetVolume", func(map[string]string) (map[string]string, error) {
+		if failures < 2 {
+			failures++
+			return nil, errors.New("overloaded")
+		}
+		return map[string]string{"CurrentVolume": "33"}, nil
+	})
+	fake.Handle("SetRelativeGroupVolume", func(map[string]string) (map[string]string, error) {
+		return nil, errors.New("overloaded")
+	})
+	c := newTestClient(t, []Option{WithRetry(RetryPolicy{Attempts: 3, Backoff: time.Millisecond})}, fake)
+	d := device(t, c, fake)
+
+	vol, err := d.Volume(ctx)
+	if err != nil {
+		t.Fatalf("Volume: %v", err)
+	}
+	if vol != 33 {
+		t.Errorf("Volume = %d, want 33", vol)
+	}
+
+	if _, err := d.AdjustGroupVolume(ctx, -5); err == nil {
+		t.Fatalf("AdjustGroupVolume succeeded")
+	}
+	n := 0
+	for _, a := range actions(fake) {
+		if a == "SetRelativeGroupVolume" {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("SetRelativeGroupVolume was sent %d times, want 1", n)
+	}
+}
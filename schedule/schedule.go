@@ -0,0 +1,202 @@
+// Package schedule plays music in the zones of a Sonos system at set times,
+// such as a playlist in the kitchen on weekday mornings:
+//
+//	s := &schedule.Scheduler{
+//		Client: client,
+//		Rules: []schedule.Rule{{
+//			When:     "30 7 * * mon-fri",
+//			Zone:     "Kitchen",
+//			Playlist: "Morning",
+//			Duration: time.Hour,
+//		}},
+//	}
+//	err := s.Run(ctx)
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dsymonds/sonos"
+)
+
+// restoreTimeout bounds how long restoring a zone may take,
+// since it may happen after the Scheduler's context is done.
+const restoreTimeout = 30 * time.Second
+
+// A Rule says to play something in a zone on a schedule.
+type Rule struct {
+	Name string // for reporting; optional
+	When string // a cron expression; see ParseSpec
+	Zone string
+
+	// What to play: a Sonos playlist or favorite with the given title, or an item.
+	// Exactly one must be set.
+	Playlist string
+	Favorite string
+	Item     *sonos.ServiceItem // e.g. from sonos.TuneInItem
+
+	Volume *int            // if non-nil, the volume to play at
+	Mode   *sonos.PlayMode // if non-nil, the play mode to set; only for playing from the queue
+
+	// Duration, if positive, is how long to play for,
+	// after which the zone is put back as it was.
+	Duration time.Duration
+}
+
+func (r Rule) String() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%s at %q", r.Zone, r.When)
+}
+
+// A Scheduler runs Rules against a Client.
+type Scheduler struct {
+	Client   *sonos.Client
+	Rules    []Rule
+	Location *time.Location // for the times in the rules; default time.Local
+
+	// OnRun, if set, is called after each rule has run, with any error.
+	// By default failures are logged to the Client's logger.
+	OnRun func(r Rule, err error)
+}
+
+// Run runs the rules until ctx is done, and then waits for any rules
+// still playing to put their zones back.
+// It returns an error, without running any rules, if any rule is invalid.
+func (s *Scheduler) Run(ctx context.Context) error {
+	specs := make([]Spec, len(s.Rules))
+	for i, r := range s.Rules {
+		if err := r.check(); err != nil {
+			return fmt.Errorf("rule %v: %w", r, err)
+		}
+		var err error
+		if specs[i], err = ParseSpec(r.When); err != nil {
+			return fmt.Errorf("rule %v: %w", r, err)
+		}
+	}
+	loc := s.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		now := time.Now().In(loc)
+		var next time.Time
+		for _, sp := range specs {
+			if t := sp.Next(now); !t.IsZero() && (next.IsZero() || t.Before(next)) {
+				next = t
+			}
+		}
+		if next.IsZero() {
+			<-ctx.Done() // nothing will run
+			return nil
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		for i, sp := range specs {
+			if !sp.Next(next.Add(-time.Minute)).Equal(next) {
+				continue
+			}
+			wg.Add(1)
+			go func(r Rule) {
+				defer wg.Done()
+				s.report(r, s.run(ctx, r))
+			}(s.Rules[i])
+		}
+	}
+}
+
+func (r Rule) check() error {
+	if r.Zone == "" {
+		return errors.New("no zone")
+	}
+	n := 0
+	for _, set := range []bool{r.Playlist != "", r.Favorite != "", r.Item != nil} {
+		if set {
+			n++
+		}
+	}
+	if n != 1 {
+		return errors.New("need exactly one of a playlist, favorite or item")
+	}
+	return nil
+}
+
+func (s *Scheduler) report(r Rule, err error) {
+	if s.OnRun != nil {
+		s.OnRun(r, err)
+	} else if err != nil {
+		s.Client.Logger().Warn("Running scheduled rule", "rule", r.String(), "err", err)
+	}
+}
+
+// run plays a rule, and with a Duration then waits and restores the zone.
+func (s *Scheduler) run(ctx context.Context, r Rule) error {
+	d, err := s.Client.ZoneDevice(ctx, r.Zone)
+	if err != nil {
+		return err
+	}
+	var snap *sonos.Snapshot
+	if r.Duration > 0 {
+		if snap, err = d.Snapshot(ctx); err != nil {
+			return fmt.Errorf("taking snapshot: %w", err)
+		}
+	}
+	err = play(ctx, d, r)
+	if snap == nil {
+		return err
+	}
+	if err == nil {
+		timer := time.NewTimer(r.Duration)
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+
+	rctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), restoreTimeout)
+	defer cancel()
+	if rerr := d.Restore(rctx, snap); rerr != nil {
+		err = errors.Join(err, fmt.Errorf("restoring %s: %w", r.Zone, rerr))
+	}
+	return err
+}
+
+func play(ctx context.Context, d *sonos.Device, r Rule) error {
+	if r.Playlist != "" {
+		return d.PlaySonosPlaylist(ctx, r.Playlist, sonos.PlayOptions{Mode: r.Mode, Volume: r.Volume})
+	}
+	if r.Volume != nil {
+		if err := d.SetVolume(ctx, *r.Volume); err != nil {
+			return err
+		}
+	}
+	if r.Favorite != "" {
+		f, err := d.FindFavorite(ctx, r.Favorite)
+		if err != nil {
+			return err
+		}
+		if err := d.PlayFavorite(ctx, f); err != nil {
+			return err
+		}
+	} else if err := d.PlayStream(ctx, *r.Item); err != nil {
+		return err
+	}
+	if r.Mode != nil {
+		return d.SetPlayMode(ctx, *r.Mode)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package schedule
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dsymonds/sonos"
+	"github.com/dsymonds/sonos/sonostest"
+)
+
+func newScheduler(t *testing.T, fake *sonostest.Device) *Scheduler {
+	t.Helper()
+	ctx := context.Background()
+	c, err := sonos.NewClientFromIPs(ctx, nil)
+	if err != nil {
+		t.Fatalf("NewClientFromIPs: %v", err)
+	}
+	if err := c.AddDeviceByURL(ctx, fake.Location()); err != nil {
+		t.Fatalf("AddDeviceByURL: %v", err)
+	}
+	return &Scheduler{Client: c}
+}
+
+func TestRulePlaylist(t *testing.T) {
+	fake := sonostest.NewDevice("Kitchen")
+	defer fake.Close()
+	fake.AddPlaylist("Morning", sonostest.Track{URI: "x-file-cifs://nas/1.mp3"}, sonostest.Track{URI: "x-file-cifs://nas/2.mp3"})
+	fake.SetQueue(sonostest.Track{URI: "x-file-cifs://nas/old.mp3"})
+	s := newScheduler(t, fake)
+
+	vol, mode := 15, sonos.Shuffle
+	err := s.run(context.Background(), Rule{Zone: "Kitchen", Playlist: "Morning", Volume: &vol, Mode: &mode})
+	if err != nil {
+		t.Fatalf("running rule: %v", err)
+	}
+	var got []string
+	for _, tr := range fake.Queue() {
+		got = append(got, tr.URI)
+	}
+	if want := []string{"x-file-cifs://nas/1.mp3", "x-file-cifs://nas/2.mp3"}; !slices.Equal(got, want) {
+		t.Errorf("queue is %q, want %q", got, want)
+	}
+	for key, want := range map[string]string{
+		"TransportState": "PLAYING",
+		"Volume":         "15",
+		"PlayMode":       "SHUFFLE_NOREPEAT",
+		"URI":            "x-rincon-queue:" + fake.UUID + "#0",
+	} {
+		if got := fake.State(key); got != want {
+			t.Errorf("%s is %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestRuleEmptyPlaylist(t *testing.T) {
+	// An empty playlist leaves the queue alone.
+	fake := sonostest.NewDevice("Kitchen")
+	defer fake.Close()
+	fake.AddPlaylist("Morning")
+	fake.SetQueue(sonostest.Track{URI: "x-file-cifs://nas/old.mp3"})
+	s := newScheduler(t, fake)
+
+	if err := s.run(context.Background(), Rule{Zone: "Kitchen", Playlist: "Morning"}); err == nil {
+		t.Errorf("running rule with empty playlist succeeded")
+	}
+	if n := len(fake.Queue()); n != 1 {
+		t.Errorf("queue has %d tracks, want 1", n)
+	}
+	if got := fake.State("TransportState"); got != "STOPPED" {
+		t.Errorf("transport state is %q, want STOPPED", got)
+	}
+}
+
+func TestRuleDuration(t *testing.T) {
+	// After the duration, the zone is put back as it was.
+	fake := sonostest.NewDevice("Kitchen")
+	defer fake.Close()
+	fake.AddPlaylist("Morning", sonostest.Track{URI: "x-file-cifs://nas/1.mp3"})
+	fake.SetQueue(sonostest.Track{URI: "x-file-cifs://nas/old.mp3"})
+	s := newScheduler(t, fake)
+
+	vol := 40
+	err := s.run(context.Background(), Rule{Zone: "Kitchen", Playlist: "Morning", Volume: &vol, Duration: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("running rule: %v", err)
+	}
+	if got := fake.Queue(); len(got) != 1 || got[0].URI != "x-file-cifs://nas/old.mp3" {
+		t.Errorf("queue is %+v, want it restored", got)
+	}
+	for key, want := range map[string]string{
+		"TransportState": "STOPPED",
+		"Volume":         "20",
+	} {
+		if got := fake.State(key); got != want {
+			t.Errorf("%s is %q, want %q", key, got, want)
+		}
+	}
+	if !slices.ContainsFunc(fake.Calls(), func(c sonostest.Call) bool { return c.Action == "Play" }) {
+		t.Errorf("rule did not play")
+	}
+}
+
+func TestRuleCheck(t *testing.T) {
+	item := &sonos.ServiceItem{URI: "x-rincon-mp3radio://example.com/live"}
+	tests := []struct {
+		r  Rule
+		ok bool
+	}{
+		{Rule{Zone: "Kitchen", Playlist: "Morning"}, true},
+		{Rule{Zone: "Kitchen", Favorite: "Radio"}, true},
+		{Rule{Zone: "Kitchen", Item: item}, true},
+		{Rule{Playlist: "Morning"}, false},
+		{Rule{Zone: "Kitchen"}, false},
+		{Rule{Zone: "Kitchen", Playlist: "Morning", Item: item}, false},
+	}
+	for _, tc := range tests {
+		if err := tc.r.check(); (err == nil) != tc.ok {
+			t.Errorf("check of %+v = %v, want ok %t", tc.r, err, tc.ok)
+		}
+	}
+}
+
+func TestRunInvalidRule(t *testing.T) {
+	fake := sonostest.NewDevice("Kitchen")
+	defer fake.Close()
+	s := newScheduler(t, fake)
+	s.Rules = []Rule{{Zone: "Kitchen", Playlist: "Morning", When: "61 * * * *"}}
+	if err := s.Run(context.Background()); err == nil {
+		t.Errorf("Run with an invalid rule succeeded")
+	}
+}
@@ -0,0 +1,206 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Spec is a set of times, parsed from a cron expression by ParseSpec.
+type Spec struct {
+	minute, hour, dom, month, dow uint64 // bit sets of the matching values
+	domStar, dowStar              bool   // whether the day fields started with "*"
+	hourStar                      bool   // whether the hour field matches every hour
+}
+
+// Names for months and days of the week, as in cron.
+var (
+	monthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	dayNames   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// Shorthands for common specs.
+var shorthands = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * sun",
+	"@monthly": "0 0 1 * *",
+}
+
+// ParseSpec parses a cron expression of five space-separated fields:
+// minute, hour, day of month, month and day of week (0 or 7 for Sunday).
+// Each field is "*", a number, a range such as "1-5", either of those
+// with a step such as "*/15" or "0-30/10", or a comma-separated list of these.
+// Months and days of the week may also be named, such as "jan" or "mon-fri",
+// and their ranges may wrap around, such as "fri-mon" or "nov-feb".
+// As in cron, if both day fields are restricted (neither starts with "*"),
+// a day matching either matches; otherwise a day must match both.
+// The shorthands "@hourly", "@daily", "@weekly" and "@monthly" are also accepted.
+func ParseSpec(s string) (Spec, error) {
+	if long, ok := shorthands[strings.ToLower(s)]; ok {
+		s = long
+	}
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return Spec{}, fmt.Errorf("cron spec %q has %d fields, want 5", s, len(fields))
+	}
+	var sp Spec
+	var err error
+	if sp.minute, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return Spec{}, fmt.Errorf("parsing minute of %q: %w", s, err)
+	}
+	if sp.hour, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return Spec{}, fmt.Errorf("parsing hour of %q: %w", s, err)
+	}
+	if sp.dom, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return Spec{}, fmt.Errorf("parsing day of month of %q: %w", s, err)
+	}
+	if sp.month, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return Spec{}, fmt.Errorf("parsing month of %q: %w", s, err)
+	}
+	if sp.dow, err = parseField(fields[4], 0, 7, dayNames); err != nil {
+		return Spec{}, fmt.Errorf("parsing day of week of %q: %w", s, err)
+	}
+	if sp.dow&(1<<7) != 0 {
+		sp.dow |= 1 // 7 is also Sunday
+	}
+	sp.domStar, sp.dowStar = strings.HasPrefix(fields[2], "*"), strings.HasPrefix(fields[4], "*")
+	sp.hourStar = sp.hour == 1<<24-1
+	return sp, nil
+}
+
+// parseField parses one field of a cron expression, whose values are in [lo,hi].
+// names, if set, are names for the values from lo, and ranges of them may wrap around.
+func parseField(f string, lo, hi int, names []string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(f, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("bad step %q", stepStr)
+			}
+		}
+		start, end := lo, hi
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if start, err = parseValue(a, lo, hi, names); err != nil {
+				return 0, err
+			}
+			end = start
+			if isRange {
+				if end, err = parseValue(b, lo, hi, names); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				end = hi // "5/10" means from 5 onwards
+			}
+			if end < start {
+				if names == nil {
+					return 0, fmt.Errorf("bad range %q", rng)
+				}
+				end += len(names) // e.g. "fri-mon"
+			}
+		}
+		for v := start; v <= end; v += step {
+			if names != nil && v >= lo+len(names) {
+				bits |= 1 << (lo + (v-lo)%len(names))
+			} else {
+				bits |= 1 << v
+			}
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, lo, hi int, names []string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(s, name) {
+			return lo + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < lo || v > hi {
+		return 0, fmt.Errorf("bad value %q; want %d-%d", s, lo, hi)
+	}
+	return v, nil
+}
+
+// Next returns the first time in the spec after t, in t's location,
+// or the zero time if there is none within five years (e.g. for "0 0 31 2 *").
+//
+// Daylight saving changes are handled as cron does. When clocks go forward,
+// times that are skipped over match at the end of the gap (e.g. 03:00 for 02:30).
+// When they go back, times that happen twice match only the first time,
+// unless the hour field is "*".
+func (sp Spec) Next(t time.Time) time.Time {
+	loc := t.Location()
+	// Step forward in absolute time, since the wall clock time may be ambiguous.
+	t = t.Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		// next is the next time to check, and want is the wall clock time
+		// it should have, which it does not if clocks went forward in between.
+		var next, want time.Time
+		switch {
+		case sp.month&(1<<uint(t.Month())) == 0:
+			next = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			want = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !sp.dayMatches(t):
+			next = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			want = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case sp.hour&(1<<uint(t.Hour())) == 0:
+			step := time.Duration(60-t.Minute()) * time.Minute
+			next, want = t.Add(step), wall(t).Add(step)
+		case sp.minute&(1<<uint(t.Minute())) == 0 || !sp.hourStar && repeated(t):
+			next, want = t.Add(time.Minute), wall(t).Add(time.Minute)
+		default:
+			return t
+		}
+		for w := want; w.Before(wall(next)); w = w.Add(time.Minute) {
+			if sp.matches(w) {
+				return next // skipped over by clocks going forward
+			}
+		}
+		// Around daylight saving changes, time.Date may step backwards.
+		if !next.After(t) {
+			next = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		}
+		t = next
+	}
+	return time.Time{}
+}
+
+// wall returns t's wall clock time, to the minute, as a time in UTC.
+func wall(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+}
+
+// repeated reports whether t's wall clock time happened earlier too,
+// because clocks went back in between.
+func repeated(t time.Time) bool {
+	_, off := t.Zone()
+	_, before := t.Add(-3 * time.Hour).Zone() // no change of clocks is longer than this
+	if before <= off {
+		return false
+	}
+	return wall(t.Add(-time.Duration(before-off) * time.Second)).Equal(wall(t))
+}
+
+// matches reports whether a wall clock time, as from wall, is in the spec.
+func (sp Spec) matches(w time.Time) bool {
+	return sp.month&(1<<uint(w.Month())) != 0 && sp.dayMatches(w) &&
+		sp.hour&(1<<uint(w.Hour())) != 0 && sp.minute&(1<<uint(w.Minute())) != 0
+}
+
+func (sp Spec) dayMatches(t time.Time) bool {
+	dom := sp.dom&(1<<uint(t.Day())) != 0
+	dow := sp.dow&(1<<uint(t.Weekday())) != 0
+	if sp.domStar || sp.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
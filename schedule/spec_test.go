@@ -0,0 +1,122 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSpecErrors(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"* * * * xyz",
+		"@yearly",
+	} {
+		if _, err := ParseSpec(s); err == nil {
+			t.Errorf("ParseSpec(%q) succeeded", s)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	at := func(s string) time.Time {
+		t.Helper()
+		tm, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	tests := []struct {
+		spec     string
+		from     string
+		want     string
+		wantNone bool
+	}{
+		{spec: "30 7 * * mon-fri", from: "2026-10-16 08:00", want: "2026-10-19 07:30"}, // Friday to Monday
+		{spec: "30 7 * * mon-fri", from: "2026-10-16 07:29", want: "2026-10-16 07:30"},
+		{spec: "*/15 * * * *", from: "2026-10-14 10:07", want: "2026-10-14 10:15"},
+		{spec: "*/15 * * * *", from: "2026-10-14 10:15", want: "2026-10-14 10:30"}, // strictly after
+		{spec: "*/15 * * * *", from: "2026-10-14 23:50", want: "2026-10-15 00:00"},
+		{spec: "5/20 * * * *", from: "2026-10-14 10:26", want: "2026-10-14 10:45"},
+		{spec: "0 9-17/4 * * *", from: "2026-10-14 13:00", want: "2026-10-14 17:00"},
+		{spec: "0,30 6 * * *", from: "2026-10-14 06:10", want: "2026-10-14 06:30"},
+		{spec: "@daily", from: "2026-12-31 12:00", want: "2027-01-01 00:00"},
+		{spec: "@monthly", from: "2026-10-14 12:00", want: "2026-11-01 00:00"},
+		{spec: "@weekly", from: "2026-10-14 12:00", want: "2026-10-18 00:00"},
+		{spec: "0 9 * * 7", from: "2026-10-14 12:00", want: "2026-10-18 09:00"}, // 7 is Sunday
+		{spec: "0 12 * * fri-mon", from: "2026-10-16 13:00", want: "2026-10-17 12:00"},
+		{spec: "0 12 * * fri-mon", from: "2026-10-20 13:00", want: "2026-10-23 12:00"}, // Tuesday to Friday
+		{spec: "0 0 1 nov-feb *", from: "2026-03-05 00:00", want: "2026-11-01 00:00"},
+		{spec: "0 0 1 nov-feb *", from: "2026-12-05 00:00", want: "2027-01-01 00:00"},
+		{spec: "0 12 13 * fri", from: "2026-10-14 00:00", want: "2026-10-16 12:00"},  // either day field
+		{spec: "0 12 13 * fri", from: "2026-11-07 00:00", want: "2026-11-13 12:00"},  // both
+		{spec: "0 12 */2 * fri", from: "2026-10-14 13:00", want: "2026-10-23 12:00"}, // "*/2" must match both
+		{spec: "0 12 */2 * fri", from: "2026-10-23 13:00", want: "2026-11-13 12:00"},
+		{spec: "0 0 29 2 *", from: "2026-03-01 00:00", want: "2028-02-29 00:00"},
+		{spec: "0 0 31 2 *", from: "2026-03-01 00:00", wantNone: true},
+	}
+	for _, tc := range tests {
+		sp, err := ParseSpec(tc.spec)
+		if err != nil {
+			t.Errorf("ParseSpec(%q): %v", tc.spec, err)
+			continue
+		}
+		got := sp.Next(at(tc.from))
+		if tc.wantNone {
+			if !got.IsZero() {
+				t.Errorf("%q.Next(%s) = %v, want none", tc.spec, tc.from, got)
+			}
+			continue
+		}
+		if want := at(tc.want); !got.Equal(want) {
+			t.Errorf("%q.Next(%s) = %v, want %v", tc.spec, tc.from, got, want)
+		}
+	}
+}
+
+func TestNextDaylightSaving(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+	edt := time.FixedZone("EDT", -4*60*60)
+	est := time.FixedZone("EST", -5*60*60)
+	tests := []struct {
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		// Clocks went forward at 02:00 on 8 March 2026.
+		{"30 2 * * *", time.Date(2026, 3, 8, 0, 0, 0, 0, est), time.Date(2026, 3, 8, 3, 0, 0, 0, edt)},
+		{"30 2 * * *", time.Date(2026, 3, 8, 3, 0, 0, 0, edt), time.Date(2026, 3, 9, 2, 30, 0, 0, edt)},
+		{"0 3 * * *", time.Date(2026, 3, 8, 0, 0, 0, 0, est), time.Date(2026, 3, 8, 3, 0, 0, 0, edt)},
+		{"*/20 * * * *", time.Date(2026, 3, 8, 1, 50, 0, 0, est), time.Date(2026, 3, 8, 3, 0, 0, 0, edt)},
+		// Clocks go back at 02:00 on 1 November 2026, so 01:00-01:59 happens twice.
+		{"30 1 * * *", time.Date(2026, 11, 1, 0, 0, 0, 0, edt), time.Date(2026, 11, 1, 1, 30, 0, 0, edt)},
+		{"30 1 * * *", time.Date(2026, 11, 1, 1, 30, 0, 0, edt), time.Date(2026, 11, 2, 1, 30, 0, 0, est)},
+		{"30 * * * *", time.Date(2026, 11, 1, 1, 30, 0, 0, edt), time.Date(2026, 11, 1, 1, 30, 0, 0, est)},
+		{"*/20 * * * *", time.Date(2026, 11, 1, 1, 50, 0, 0, edt), time.Date(2026, 11, 1, 1, 0, 0, 0, est)},
+		{"0 2 * * *", time.Date(2026, 11, 1, 1, 30, 0, 0, edt), time.Date(2026, 11, 1, 2, 0, 0, 0, est)},
+	}
+	for _, tc := range tests {
+		sp, err := ParseSpec(tc.spec)
+		if err != nil {
+			t.Errorf("ParseSpec(%q): %v", tc.spec, err)
+			continue
+		}
+		from := tc.from.In(loc)
+		if got := sp.Next(from); !got.Equal(tc.want) {
+			t.Errorf("%q.Next(%v) = %v, want %v", tc.spec, from, got, tc.want.In(loc))
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ServiceItem is content, such as from a music service, ready to add to a queue.
+type ServiceItem struct {
+	URI      string
+	Metadata string // DIDL-Lite XML, identifying the content and the service account
+}
+
+// AddToQueue adds an item to the device's queue.
+func (d *Device) AddToQueue(ctx context.Context, item ServiceItem, opts EnqueueOptions) (EnqueueResult, error) {
+	return d.addURIToQueue(ctx, item.URI, item.Metadata, opts)
+}
+
+// NewServiceItem builds a ServiceItem for content from the identified music service.
+// itemID identifies the content to the service, class is its UPnP class
+// (e.g. "object.item.audioItem.musicTrack"), and title is optional.
+// The helpers such as SpotifyItem are simpler for the services they support.
+func NewServiceItem(serviceID int, uri, itemID, class, title string) ServiceItem {
+	// The account descriptor names the "service type", derived from the service ID.
+	// The "-0-Token" suffix selects the first account for the service.
+	st := strconv.Itoa(MusicService{ID: serviceID}.ServiceType())
+	var buf strings.Builder
+	buf.WriteString(didlHeader)
+	fmt.Fprintf(&buf, `<item id="%s" parentID="-1" restricted="true">`, escapeXML(itemID))
+	fmt.Fprintf(&buf, `<dc:title>%s</dc:title><upnp:class>%s</upnp:class>`, escapeXML(title), escapeXML(class))
+	buf.WriteString(`<desc id="cdudn" nameSpace="` + nsR + `">SA_RINCON` + st + `_X_#Svc` + st + `-0-Token</desc></item></DIDL-Lite>`)
+	return ServiceItem{URI: uri, Metadata: buf.String()}
+}
+
+// Usual music service IDs. The ID for an account can differ by region
+// or account age; Device.MusicServices lists the IDs that a household uses.
+const (
+	SpotifyServiceID     = 12 // some older accounts, especially in Europe, use 9
+	AppleMusicServiceID  = 204
+	DeezerServiceID      = 2
+	AmazonMusicServiceID = 201
+	SonosRadioServiceID  = 303
+)
+
+// ContentKind is a kind of music service content.
+type ContentKind string
+
+const (
+	KindTrack    ContentKind = "track"
+	KindAlbum    ContentKind = "album"
+	KindPlaylist ContentKind = "playlist"
+	KindEpisode  ContentKind = "episode" // a podcast episode
+)
+
+// contentFormat describes how Sonos refers to a kind of content from a music service.
+// In each format, %[1]s is the service's content ID (URL-escaped) and %[2]d is the service ID.
+type contentFormat struct {
+	uri, itemID, class string
+}
+
+const (
+	classTrack    = "object.item.audioItem.musicTrack"
+	classAlbum    = "object.container.album.musicAlbum"
+	classPlaylist = "object.container.playlistContainer"
+)
+
+// contentFormats holds the formats for each supported music service, by service name.
+var contentFormats = map[string]map[ContentKind]contentFormat{
+	"Spotify": {
+		KindTrack:    {"x-sonos-spotify:spotify%%3atrack%%3a%[1]s?sid=%[2]d&flags=8224&sn=1", "00032020spotify%%3atrack%%3a%[1]s", classTrack},
+		KindAlbum:    {"x-rincon-cpcontainer:1004206cspotify%%3aalbum%%3a%[1]s", "1004206cspotify%%3aalbum%%3a%[1]s", classAlbum},
+		KindPlaylist: {"x-rincon-cpcontainer:1006206cspotify%%3aplaylist%%3a%[1]s", "1006206cspotify%%3aplaylist%%3a%[1]s", classPlaylist},
+		// Sonos plays episodes as tracks, but they have their own URIs.
+		KindEpisode: {"x-sonos-spotify:spotify%%3aepisode%%3a%[1]s?sid=%[2]d&flags=8224&sn=1", "00032020spotify%%3aepisode%%3a%[1]s", classTrack},
+	},
+	"Apple Music": {
+		KindTrack:    {"x-sonos-http:song%%3a%[1]s.mp4?sid=%[2]d&flags=8224&sn=1", "10032020song%%3a%[1]s", classTrack},
+		KindAlbum:    {"x-rincon-cpcontainer:1004206calbum%%3a%[1]s", "1004206calbum%%3a%[1]s", classAlbum},
+		KindPlaylist: {"x-rincon-cpcontainer:1006206cplaylist%%3a%[1]s", "1006206cplaylist%%3a%[1]s", classPlaylist},
+	},
+	"Deezer": {
+		KindTrack:    {"x-sonos-http:tr%%3a%[1]s.mp3?sid=%[2]d&flags=8224&sn=1", "10032020tr%%3a%[1]s", classTrack},
+		KindAlbum:    {"x-rincon-cpcontainer:1004006calbum-%[1]s", "1004006calbum-%[1]s", classAlbum},
+		KindPlaylist: {"x-rincon-cpcontainer:1006006cplaylist_spotify%%3aplaylist-%[1]s", "1006006cplaylist_spotify%%3aplaylist-%[1]s", classPlaylist},
+	},
+	// Amazon Music tracks need their album too, so only albums and playlists are supported.
+	"Amazon Music": {
+		KindAlbum:    {"x-rincon-cpcontainer:1004206ccatalog%%2falbums%%2f%[1]s%%2f%%23album_desc", "1004206ccatalog%%2falbums%%2f%[1]s%%2f%%23album_desc", classAlbum},
+		KindPlaylist: {"x-rincon-cpcontainer:1006206ccatalog%%2fplaylists%%2f%[1]s%%2f%%23playlist_desc", "1006206ccatalog%%2fplaylists%%2f%[1]s%%2f%%23playlist_desc", classPlaylist},
+	},
+}
+
+// contentItem builds a ServiceItem for content with the given ID
+// from the named music service, whose account uses serviceID.
+func contentItem(service string, serviceID int, kind ContentKind, id string) (ServiceItem, error) {
+	f, ok := contentFormats[service][kind]
+	if !ok {
+		return ServiceItem{}, fmt.Errorf("unsupported %s content type %q", service, kind)
+	}
+	if id == "" {
+		return ServiceItem{}, fmt.Errorf("empty %s %s ID", service, kind)
+	}
+	enc := url.QueryEscape(id)
+	uri := fmt.Sprintf(f.uri, enc, serviceID)
+	itemID := fmt.Sprintf(f.itemID, enc, serviceID)
+	return NewServiceItem(serviceID, uri, itemID, f.class, ""), nil
+}
+
+// SpotifyItem converts a Spotify URI (e.g. "spotify:track:6rqhFgbbKwnb9MLmUQDhG6")
+// or share link (e.g. "https://open.spotify.com/album/1DFixLWuPkv3KT3TnV35m3?si=x")
+// for a track, episode, album or playlist into an item that can be added to a queue.
+// It assumes the account uses SpotifyServiceID; see SpotifyServiceItem.
+func SpotifyItem(link string) (ServiceItem, error) {
+	return SpotifyServiceItem(SpotifyServiceID, link)
+}
+
+// SpotifyServiceItem is like SpotifyItem, for an account with the given service ID.
+func SpotifyServiceItem(serviceID int, link string) (ServiceItem, error) {
+	kind, id, err := parseSpotifyLink(link)
+	if err != nil {
+		return ServiceItem{}, err
+	}
+	return contentItem("Spotify", serviceID, ContentKind(kind), id)
+}
+
+// AppleMusicItem returns an item for Apple Music content with the given ID
+// (e.g. the "1440857781" of "https://music.apple.com/us/album/x/1440857781").
+// serviceID is usually AppleMusicServiceID.
+func AppleMusicItem(serviceID int, kind ContentKind, id string) (ServiceItem, error) {
+	return contentItem("Apple Music", serviceID, kind, id)
+}
+
+// DeezerItem returns an item for Deezer content with the given ID.
+// serviceID is usually DeezerServiceID.
+func DeezerItem(serviceID int, kind ContentKind, id string) (ServiceItem, error) {
+	return contentItem("Deezer", serviceID, kind, id)
+}
+
+// AmazonMusicItem returns an item for an Amazon Music album or playlist with the given ID (ASIN).
+// serviceID is usually AmazonMusicServiceID.
+func AmazonMusicItem(serviceID int, kind ContentKind, id string) (ServiceItem, error) {
+	return contentItem("Amazon Music", serviceID, kind, id)
+}
+
+// parseSpotifyLink extracts the content type and ID from a Spotify URI or share link.
+func parseSpotifyLink(link string) (kind, id string, err error) {
+	var parts []string
+	if rest, ok := strings.CutPrefix(link, "spotify:"); ok {
+		parts = strings.Split(rest, ":")
+	} else {
+		u, err := url.Parse(link)
+		if err != nil || u.Host != "open.spotify.com" {
+			return "", "", fmt.Errorf("%q is not a Spotify URI or link", link)
+		}
+		parts = strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) > 0 && strings.HasPrefix(parts[0], "intl-") {
+			parts = parts[1:]
+		}
+	}
+	// Legacy playlist URIs look like "spotify:user:<name>:playlist:<id>".
+	if len(parts) == 4 && parts[0] == "user" {
+		parts = parts[2:]
+	}
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", errors.New("malformed Spotify URI or link")
+	}
+	return parts[0], parts[1], nil
+}
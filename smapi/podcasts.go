@@ -0,0 +1,38 @@
+package smapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/dsymonds/sonos"
+)
+
+// SearchPodcasts searches the service's podcasts for a term.
+// Not every service has a podcast search category.
+func (c *Client) SearchPodcasts(ctx context.Context, term string, index, count int) (*Page, error) {
+	return c.Search(ctx, "podcasts", term, index, count)
+}
+
+// Episodes lists the episodes of a podcast found by SearchPodcasts or Browse.
+// Podcasts are containers of type "podcast", and their episodes are items
+// of type "podcast" or, for some services, "audiobook".
+func (c *Client) Episodes(ctx context.Context, podcast Item, index, count int) (*Page, error) {
+	if !podcast.Container {
+		return nil, fmt.Errorf("%q is not a podcast", podcast.Title)
+	}
+	return c.Browse(ctx, podcast.ID, index, count)
+}
+
+// QueueEpisode converts a podcast episode into an item that can be added to a player's queue
+// with Device.AddToQueue. Players show episodes differently to music tracks,
+// so they need their own class of metadata.
+func (c *Client) QueueEpisode(ep Item) (sonos.ServiceItem, error) {
+	if ep.Container || !ep.CanPlay {
+		return sonos.ServiceItem{}, fmt.Errorf("%q is not a playable episode", ep.Title)
+	}
+	enc := url.QueryEscape(ep.ID)
+	sid := c.Service.ID
+	uri := fmt.Sprintf("x-sonos-http:%s?sid=%d&flags=8224&sn=1", enc, sid)
+	return sonos.NewServiceItem(sid, uri, "10032020"+enc, "object.item.audioItem.musicTrack.recentShow", ep.Title), nil
+}
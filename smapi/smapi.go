@@ -0,0 +1,311 @@
+// Package smapi is a client for the Sonos Music API (SMAPI),
+// which music services implement so that Sonos players can browse
+// and search their catalogs.
+//
+// A Client browses a service as a particular player would, identifying
+// itself with the player's credentials. Services with anonymous access
+// need only the player's identity (see DeviceCredentials). Services that need
+// an account require a login token, which players do not reveal; it must
+// be obtained by running the service's DeviceLink or AppLink flow.
+package smapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dsymonds/sonos"
+)
+
+const smapiNS = "http://www.sonos.com/Services/1.1"
+
+// Credentials identify a player, and optionally an account, to a music service.
+type Credentials struct {
+	DeviceID    string // the player's serial number
+	HouseholdID string
+
+	// For services using session IDs (auth type "UserId").
+	SessionID string
+
+	// For services using login tokens (auth types "DeviceLink" and "AppLink").
+	Token string
+	Key   string
+}
+
+// DeviceCredentials returns the credentials identifying d, without any account.
+func DeviceCredentials(ctx context.Context, d *sonos.Device) (Credentials, error) {
+	info, err := d.Info(ctx)
+	if err != nil {
+		return Credentials{}, err
+	}
+	hh, err := d.HouseholdID(ctx)
+	if err != nil {
+		return Credentials{}, err
+	}
+	return Credentials{
+		DeviceID:    info.SerialNumber,
+		HouseholdID: hh,
+	}, nil
+}
+
+// Client talks to a single music service.
+type Client struct {
+	Service     sonos.MusicService
+	Credentials Credentials
+	HTTPClient  *http.Client // if nil, http.DefaultClient is used
+}
+
+// Item is an entry in a music service's catalog.
+type Item struct {
+	ID          string
+	Type        string // e.g. "track", "album", "playlist", "stream", "container"
+	Title       string
+	Artist      string
+	Album       string
+	AlbumArtURI string
+	CanPlay     bool
+	Container   bool // whether the item can be browsed
+}
+
+// Page is a page of results from browsing or searching.
+type Page struct {
+	Index, Total int
+	Items        []Item
+}
+
+// Fault is an error reported by a music service.
+type Fault struct {
+	Code   string // e.g. "Client.LoginUnauthorized"
+	String string
+}
+
+func (f *Fault) Error() string { return fmt.Sprintf("SMAPI fault %s: %s", f.Code, f.String) }
+
+// Browse lists the children of a catalog item. The catalog's root has ID "root".
+func (c *Client) Browse(ctx context.Context, id string, index, count int) (*Page, error) {
+	var resp struct {
+		Result result `xml:"getMetadataResult"`
+	}
+	err := c.call(ctx, "getMetadata", struct {
+		XMLName xml.Name `xml:"http://www.sonos.com/Services/1.1 getMetadata"`
+		ID      string   `xml:"id"`
+		Index   int      `xml:"index"`
+		Count   int      `xml:"count"`
+	}{ID: id, Index: index, Count: count}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("browsing %s: %w", id, err)
+	}
+	return resp.Result.page(), nil
+}
+
+// Search searches a category of the catalog (e.g. "artists", "albums", "tracks";
+// the categories vary by service) for a term.
+func (c *Client) Search(ctx context.Context, category, term string, index, count int) (*Page, error) {
+	var resp struct {
+		Result result `xml:"searchResult"`
+	}
+	err := c.call(ctx, "search", struct {
+		XMLName xml.Name `xml:"http://www.sonos.com/Services/1.1 search"`
+		ID      string   `xml:"id"`
+		Term    string   `xml:"term"`
+		Index   int      `xml:"index"`
+		Count   int      `xml:"count"`
+	}{ID: category, Term: term, Index: index, Count: count}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("searching %s for %q: %w", category, term, err)
+	}
+	return resp.Result.page(), nil
+}
+
+// QueueItem converts a catalog item into an item that can be added to a player's queue
+// with Device.AddToQueue.
+func (c *Client) QueueItem(it Item) (sonos.ServiceItem, error) {
+	if !it.CanPlay {
+		return sonos.ServiceItem{}, fmt.Errorf("%q cannot be played", it.Title)
+	}
+	if !it.Container && (it.Type == "podcast" || it.Type == "audiobook") {
+		return c.QueueEpisode(it)
+	}
+	enc := url.QueryEscape(it.ID)
+	sid := c.Service.ID
+	var uri, itemID, class string
+	switch it.Type {
+	case "track", "other":
+		uri = fmt.Sprintf("x-sonos-http:%s?sid=%d&flags=8224&sn=1", enc, sid)
+		itemID, class = "10032020"+enc, "object.item.audioItem.musicTrack"
+	case "stream", "program":
+		uri = fmt.Sprintf("x-sonosapi-stream:%s?sid=%d&flags=8224&sn=1", enc, sid)
+		itemID, class = "10092020"+enc, "object.item.audioItem.audioBroadcast"
+	case "album":
+		uri = "x-rincon-cpcontainer:0004206c" + enc
+		itemID, class = "0004206c"+enc, "object.container.album.musicAlbum"
+	case "playlist", "albumList", "trackList":
+		uri = "x-rincon-cpcontainer:0006206c" + enc
+		itemID, class = "0006206c"+enc, "object.container.playlistContainer"
+	default:
+		uri = "x-rincon-cpcontainer:000d206c" + enc
+		itemID, class = "000d206c"+enc, "object.container"
+	}
+	return sonos.NewServiceItem(sid, uri, itemID, class, it.Title), nil
+}
+
+// result is the common shape of getMetadata and search results.
+type result struct {
+	Index       int `xml:"index"`
+	Total       int `xml:"total"`
+	Collections []struct {
+		ID          string `xml:"id"`
+		ItemType    string `xml:"itemType"`
+		Title       string `xml:"title"`
+		Artist      string `xml:"artist"`
+		AlbumArtURI string `xml:"albumArtURI"`
+		CanPlay     bool   `xml:"canPlay"`
+	} `xml:"mediaCollection"`
+	Metadata []struct {
+		ID       string `xml:"id"`
+		ItemType string `xml:"itemType"`
+		Title    string `xml:"title"`
+		Track    struct {
+			Artist      string `xml:"artist"`
+			Album       string `xml:"album"`
+			AlbumArtURI string `xml:"albumArtURI"`
+		} `xml:"trackMetadata"`
+		Stream struct {
+			Logo string `xml:"logo"`
+		} `xml:"streamMetadata"`
+	} `xml:"mediaMetadata"`
+}
+
+func (r *result) page() *Page {
+	p := &Page{Index: r.Index, Total: r.Total}
+	for _, mc := range r.Collections {
+		p.Items = append(p.Items, Item{
+			ID:          mc.ID,
+			Type:        mc.ItemType,
+			Title:       mc.Title,
+			Artist:      mc.Artist,
+			AlbumArtURI: mc.AlbumArtURI,
+			CanPlay:     mc.CanPlay,
+			Container:   true,
+		})
+	}
+	for _, mm := range r.Metadata {
+		art := mm.Track.AlbumArtURI
+		if art == "" {
+			art = mm.Stream.Logo
+		}
+		p.Items = append(p.Items, Item{
+			ID:          mm.ID,
+			Type:        mm.ItemType,
+			Title:       mm.Title,
+			Artist:      mm.Track.Artist,
+			Album:       mm.Track.Album,
+			AlbumArtURI: art,
+			CanPlay:     true,
+		})
+	}
+	return p
+}
+
+// call makes a SMAPI SOAP request.
+func (c *Client) call(ctx context.Context, action string, in, out interface{}) error {
+	endpoint := c.Service.SecureURI
+	if endpoint == "" {
+		endpoint = c.Service.URI
+	}
+	if endpoint == "" {
+		return errors.New("music service has no SMAPI endpoint")
+	}
+
+	type loginToken struct {
+		Token       string `xml:"token"`
+		Key         string `xml:"key"`
+		HouseholdID string `xml:"householdId"`
+	}
+	creds := struct {
+		XMLName        xml.Name    `xml:"http://www.sonos.com/Services/1.1 credentials"`
+		DeviceID       string      `xml:"deviceId"`
+		DeviceProvider string      `xml:"deviceProvider"`
+		SessionID      string      `xml:"sessionId,omitempty"`
+		LoginToken     *loginToken `xml:"loginToken,omitempty"`
+	}{
+		DeviceID:       c.Credentials.DeviceID,
+		DeviceProvider: "Sonos",
+		SessionID:      c.Credentials.SessionID,
+	}
+	if c.Credentials.Token != "" {
+		creds.LoginToken = &loginToken{
+			Token:       c.Credentials.Token,
+			Key:         c.Credentials.Key,
+			HouseholdID: c.Credentials.HouseholdID,
+		}
+	}
+	env := struct {
+		XMLName xml.Name    `xml:"http://schemas.xmlsoap.org/soap/envelope/ Envelope"`
+		Header  interface{} `xml:"Header"`
+		Body    interface{} `xml:"Body"`
+	}{
+		Header: struct{ Creds interface{} }{creds},
+		Body:   struct{ In interface{} }{in},
+	}
+	body, err := xml.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+	req.Header.Set("SOAPAction", `"`+smapiNS+"#"+action+`"`)
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	var respEnv struct {
+		Body struct {
+			Fault *struct {
+				Code   string `xml:"faultcode"`
+				String string `xml:"faultstring"`
+			} `xml:"Fault"`
+			Inner []byte `xml:",innerxml"`
+		} `xml:"Body"`
+	}
+	if err := xml.Unmarshal(respBody, &respEnv); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s: %s", action, resp.Status)
+		}
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	if f := respEnv.Body.Fault; f != nil {
+		code := f.Code
+		if _, after, ok := strings.Cut(code, ":"); ok {
+			code = after // drop the namespace prefix
+		}
+		return &Fault{Code: code, String: f.String}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", action, resp.Status)
+	}
+	if err := xml.Unmarshal(respEnv.Body.Inner, out); err != nil {
+		return fmt.Errorf("decoding %s response: %w", action, err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package sonos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// A Snapshot is what a zone was doing, for putting back with Device.Restore
+// after interrupting it, such as to play something on a schedule.
+type Snapshot struct {
+	State    TransportState
+	Media    MediaInfo
+	Position PositionInfo
+	Mode     PlayMode
+	Queue    []QueueItem
+	Volume   int
+	Mute     bool
+}
+
+// Snapshot records what the device is playing, and its queue, play mode and volume.
+// For a group member, what is playing and the queue are those of the group's coordinator.
+func (d *Device) Snapshot(ctx context.Context) (*Snapshot, error) {
+	coord, err := d.coordinator(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if s.State, err = coord.TransportState(ctx); err != nil {
+		return nil, err
+	}
+	if s.Media, err = coord.MediaInfo(ctx); err != nil {
+		return nil, err
+	}
+	if s.Position, err = coord.PositionInfo(ctx); err != nil {
+		return nil, err
+	}
+	if s.Mode, err = coord.PlayMode(ctx); err != nil {
+		return nil, err
+	}
+	if s.Queue, err = coord.Queue(ctx); err != nil {
+		return nil, err
+	}
+	if s.Volume, err = d.Volume(ctx); err != nil {
+		return nil, err
+	}
+	if s.Mute, err = d.Mute(ctx); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Restore puts the device back as it was when the snapshot was taken:
+// its queue, what it was playing and where, its play mode and volume,
+// and whether it was playing.
+// For a group member, the queue and playback are restored on the group's coordinator.
+func (d *Device) Restore(ctx context.Context, s *Snapshot) error {
+	coord, err := d.coordinator(ctx)
+	if err != nil {
+		return err
+	}
+	if err := coord.ClearQueue(ctx); err != nil {
+		return err
+	}
+	if len(s.Queue) > 0 {
+		items := make([]ServiceItem, len(s.Queue))
+		for i, it := range s.Queue {
+			items[i] = ServiceItem{URI: it.URI, Metadata: it.Metadata}
+		}
+		if _, err := coord.AddItemsToQueue(ctx, items, EnqueueOptions{}); err != nil {
+			return fmt.Errorf("restoring queue: %w", err)
+		}
+	}
+
+	switch src := s.Media.Source(); src {
+	case SourceNone, SourceQueue:
+		// If nothing was selected, the queue will do.
+		if err := coord.selectQueue(ctx); err != nil {
+			return err
+		}
+		// Play modes only apply to the queue.
+		if err := coord.SetPlayMode(ctx, s.Mode); err != nil {
+			return err
+		}
+		if src == SourceQueue && s.Position.Track > 0 && len(s.Queue) > 0 {
+			if err := coord.seek(ctx, "TRACK_NR", strconv.Itoa(s.Position.Track)); err != nil {
+				return err
+			}
+			if s.Position.Duration > 0 && s.Position.Position > 0 {
+				if err := coord.seek(ctx, "REL_TIME", formatHMS(s.Position.Position)); err != nil {
+					return err
+				}
+			}
+		}
+	default:
+		if err := coord.setAVTransportURI(ctx, s.Media.URI, s.Media.Metadata); err != nil {
+			return fmt.Errorf("setting URI: %w", err)
+		}
+	}
+	if err := d.SetVolume(ctx, s.Volume); err != nil {
+		return err
+	}
+	if err := d.SetMute(ctx, s.Mute); err != nil {
+		return err
+	}
+
+	if s.State == Playing || s.State == Transitioning {
+		return coord.Play(ctx)
+	}
+	// Changing the URI leaves the device stopped, but stop it in case it was not changed.
+	if err := coord.Stop(ctx); err != nil && ErrorCode(err) != ErrCodeTransitionNotAvailable {
+		return err
+	}
+	return nil
+}
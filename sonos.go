@@ -2,11 +2,15 @@ package sonos
 
 import (
 	"context"
-	"encoding/xml"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/huin/goupnp"
@@ -14,60 +18,418 @@ import (
 	"github.com/huin/goupnp/soap"
 )
 
+// Service types of the UPnP services on Sonos devices, for use with Device.Action.
+// Not every device has every service.
 const (
-	devPropertiesService = "urn:schemas-upnp-org:service:DeviceProperties:1"
+	AVTransportService       = av1.URN_AVTransport_1
+	ConnectionManagerService = av1.URN_ConnectionManager_1
+	ContentDirectoryService  = av1.URN_ContentDirectory_1
+	RenderingControlService  = av1.URN_RenderingControl_1
+
+	AlarmClockService            = "urn:schemas-upnp-org:service:AlarmClock:1"
+	AudioInService               = "urn:schemas-upnp-org:service:AudioIn:1"
+	DevicePropertiesService      = "urn:schemas-upnp-org:service:DeviceProperties:1"
+	GroupManagementService       = "urn:schemas-upnp-org:service:GroupManagement:1"
+	GroupRenderingControlService = "urn:schemas-upnp-org:service:GroupRenderingControl:1"
+	HTControlService             = "urn:schemas-upnp-org:service:HTControl:1"
+	MusicServicesService         = "urn:schemas-upnp-org:service:MusicServices:1"
+	QueueService                 = "urn:schemas-sonos-com:service:Queue:1"
+	SystemPropertiesService      = "urn:schemas-upnp-org:service:SystemProperties:1"
+	ZoneGroupTopologyService     = "urn:schemas-upnp-org:service:ZoneGroupTopology:1"
 )
 
+// A Client controls the devices of a Sonos system.
+// It is safe for concurrent use by multiple goroutines,
+// as are the Devices that it returns.
 type Client struct {
-	devices []*goupnp.Device
-	zones   map[string][]*goupnp.Device // devices, grouped by zone
+	opts   options
+	hc     *http.Client
+	logger *slog.Logger
+
+	mu          sync.Mutex
+	devices     []*goupnp.Device
+	zones       map[string][]*goupnp.Device // devices, grouped by zone
+	meta        map[string]*deviceMeta      // by UDN
+	soapClients map[string]*soap.SOAPClient // by UDN and service type
+	maxVolumes  map[string]int              // by UDN
+	limiters    map[string]*limiter         // by UDN
+	paused      map[string]bool             // UDNs of coordinators paused by PauseAll
+	probeErrs   []ProbeError
 }
 
-func Discover(ctx context.Context) (*Client, error) {
+// defaultHTTPClient is used for all requests to devices.
+// Its transport keeps a few idle connections to each device for reuse.
+var defaultHTTPClient = &http.Client{
+	Transport: func() http.RoundTripper {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.MaxIdleConnsPerHost = 4
+		return t
+	}(),
+}
+
+// deviceMeta is what a Client knows about a device,
+// beyond what is in its goupnp.Device.
+type deviceMeta struct {
+	loc  *url.URL // location of the device description
+	desc description
+	icon string // room icon from the zone attributes, if known
+}
+
+func newClient(opts []Option) *Client {
 	c := &Client{
-		zones: make(map[string][]*goupnp.Device),
+		hc:          defaultHTTPClient,
+		logger:      slog.Default(),
+		zones:       make(map[string][]*goupnp.Device),
+		meta:        make(map[string]*deviceMeta),
+		soapClients: make(map[string]*soap.SOAPClient),
+		maxVolumes:  make(map[string]int),
+		limiters:    make(map[string]*limiter),
 	}
+	for _, opt := range opts {
+		opt(&c.opts)
+	}
+	if c.opts.hc != nil {
+		c.hc = c.opts.hc
+	}
+	if c.opts.logger != nil {
+		c.logger = c.opts.logger
+	}
+	return c
+}
 
-	mrds, err := goupnp.DiscoverDevices(devPropertiesService)
+// Discover finds the Sonos devices on the network.
+// Devices are probed concurrently once found. If ctx is done while probing,
+// Discover returns a Client with the devices probed so far, along with the context's error.
+// Devices that could not be probed are reported by the Client's ProbeErrors method.
+func Discover(ctx context.Context, opts ...Option) (*Client, error) {
+	c := newClient(opts)
+
+	if c.opts.cache != nil {
+		ok, err := c.loadCache(ctx)
+		if err != nil {
+			c.logger.WarnContext(ctx, "Loading discovery cache", "err", err)
+		}
+		if ok {
+			return c, nil
+		}
+		c = newClient(opts)
+	}
+
+	locs, err := c.search(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("discovering AV1: %w", err)
+		return nil, fmt.Errorf("discovering devices: %w", err)
 	}
-	for _, mrd := range mrds {
-		if mrd.Err != nil {
-			log.Printf("Probing AV1 at %s: %v", mrd.Location, mrd.Err)
-			continue
+	c.probeErrs = c.addDevices(ctx, locs, nil)
+	for _, pe := range c.probeErrs {
+		c.logger.WarnContext(ctx, "Adding device", "location", pe.Location, "err", pe.Err)
+	}
+	if err := ctx.Err(); err != nil {
+		return c, fmt.Errorf("probing devices: %w", err)
+	}
+
+	if c.opts.cache != nil {
+		if err := c.opts.cache.Save(c.cacheEntries()); err != nil {
+			c.logger.WarnContext(ctx, "Saving discovery cache", "err", err)
 		}
-		dev := &mrd.Root.Device
-		// Only try to work with Sonos (or SYMFONISK) devices.
-		if !strings.Contains(dev.Manufacturer, "Sonos, Inc.") {
-			continue
+	}
+
+	return c, nil
+}
+
+// search finds the locations of devices on the network.
+func (c *Client) search(ctx context.Context) ([]*url.URL, error) {
+	if !c.opts.mdns {
+		return ssdpSearch(ctx, DevicePropertiesService, c.opts)
+	}
+
+	// Do both searches in parallel, and merge the results.
+	var (
+		mlocs []*url.URL
+		merr  error
+		done  = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		mlocs, merr = mdnsSearch(ctx, c.opts)
+	}()
+	locs, err := ssdpSearch(ctx, DevicePropertiesService, c.opts)
+	<-done
+	if err != nil && merr != nil {
+		return nil, err
+	}
+	hosts := make(map[string]bool)
+	for _, loc := range locs {
+		hosts[loc.Host] = true
+	}
+	for _, loc := range mlocs {
+		if !hosts[loc.Host] {
+			locs = append(locs, loc)
 		}
-		c.devices = append(c.devices, dev)
+	}
+	return locs, nil
+}
 
-		svcs := dev.FindService(devPropertiesService)
-		if len(svcs) == 0 {
-			continue
+// NewClientFromIPs returns a Client for the devices at the given IP addresses.
+// It does not use SSDP, so it works on networks where multicast is blocked.
+func NewClientFromIPs(ctx context.Context, ips []string, opts ...Option) (*Client, error) {
+	c := newClient(opts)
+	for _, ip := range ips {
+		if err := c.AddDeviceByIP(ctx, ip); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// AddDeviceByIP adds the device at the given IP address to the Client.
+func (c *Client) AddDeviceByIP(ctx context.Context, ip string) error {
+	loc := &url.URL{
+		Scheme: "http",
+		Host:   net.JoinHostPort(ip, "1400"),
+		Path:   "/xml/device_description.xml",
+	}
+	if err := c.addDevice(ctx, loc, ""); err != nil {
+		return fmt.Errorf("adding device at %s: %w", ip, err)
+	}
+	return nil
+}
+
+// AddDeviceByURL adds the device with the description at the given URL to the Client.
+// This is mostly useful for testing (see package sonostest);
+// for real devices, AddDeviceByIP is simpler.
+func (c *Client) AddDeviceByURL(ctx context.Context, location string) error {
+	loc, err := url.Parse(location)
+	if err != nil {
+		return fmt.Errorf("parsing device location: %w", err)
+	}
+	if err := c.addDevice(ctx, loc, ""); err != nil {
+		return fmt.Errorf("adding device at %s: %w", location, err)
+	}
+	return nil
+}
+
+// maxProbes is how many devices are probed at once.
+const maxProbes = 8
+
+// ProbeError reports a device that was found but could not be added to a Client.
+type ProbeError struct {
+	Location string // URL of the device description
+	Err      error
+}
+
+func (pe ProbeError) Error() string { return fmt.Sprintf("probing %s: %v", pe.Location, pe.Err) }
+func (pe ProbeError) Unwrap() error { return pe.Err }
+
+// ProbeErrors returns the devices that Discover found but could not add.
+func (c *Client) ProbeErrors() []ProbeError {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ProbeError(nil), c.probeErrs...)
+}
+
+// addDevices adds the devices at the given locations, probing several at once.
+// If zones is non-nil, it holds the known zone name for each location.
+// It returns the errors for the devices that could not be added,
+// ignoring those that are deliberately excluded.
+func (c *Client) addDevices(ctx context.Context, locs []*url.URL, zones []string) []ProbeError {
+	var (
+		mu   sync.Mutex
+		errs []ProbeError
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, maxProbes)
+	)
+	for i, loc := range locs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return errs
 		}
-		sc := svcs[0].NewSOAPClient()
-		var resp struct {
-			CurrentZoneName string
+		var zone string
+		if zones != nil {
+			zone = zones[i]
 		}
-		err := sc.PerformActionCtx(ctx, svcs[0].ServiceType, "GetZoneAttributes", struct{}{}, &resp)
+		loc := loc
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem }()
+			defer wg.Done()
+			err := c.addDevice(ctx, loc, zone)
+			if err == nil || err == errNotSonos || err == errOtherHousehold {
+				return
+			}
+			mu.Lock()
+			errs = append(errs, ProbeError{Location: loc.String(), Err: err})
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+var (
+	errNotSonos       = errors.New("not a Sonos device")
+	errOtherHousehold = errors.New("device is in a different household")
+)
+
+// addDevice adds the device with the description at loc.
+// The device is asked for its zone name and icon, since rooms may have been renamed
+// since zone was found (e.g. from a cache); zone is used for devices that cannot be asked.
+func (c *Client) addDevice(ctx context.Context, loc *url.URL, zone string) error {
+	root, desc, err := fetchRootDevice(ctx, c.hc, loc)
+	if err != nil {
+		return err
+	}
+	dev := &root.Device
+	// Only try to work with Sonos (or SYMFONISK) devices.
+	if !strings.Contains(dev.Manufacturer, "Sonos, Inc.") {
+		return errNotSonos
+	}
+	if c.hasDevice(dev.UDN) {
+		return nil
+	}
+	// Probe through the client, so that its HTTP client, timeouts, retries and tracing apply.
+	probe := &Device{c: c, dev: dev}
+	if c.opts.household != "" {
+		hh, err := probe.HouseholdID(ctx)
 		if err != nil {
-			log.Printf("getting zone attributes: %v", err)
-			continue
+			return err
+		}
+		if hh != c.opts.household {
+			return errOtherHousehold
+		}
+	}
+	var icon string
+	if len(dev.FindService(DevicePropertiesService)) > 0 {
+		attrs, err := probe.ZoneAttributes(ctx)
+		if err != nil {
+			return err
+		}
+		zone, icon = attrs.Name, attrs.Icon
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, d := range c.devices {
+		if d.UDN == dev.UDN {
+			return nil // added concurrently
 		}
-		zone := resp.CurrentZoneName
+	}
+	c.devices = append(c.devices, dev)
+	c.meta[dev.UDN] = &deviceMeta{loc: loc, desc: desc, icon: icon}
+	if zone != "" {
 		c.zones[zone] = append(c.zones[zone], dev)
 	}
+	return nil
+}
 
-	return c, nil
+func (c *Client) hasDevice(udn string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lookup(udn) != nil
+}
+
+// zoneOf returns the zone of the device with the given UDN, or the empty string.
+// The caller must hold c.mu.
+func (c *Client) zoneOf(udn string) string {
+	for zone, devs := range c.zones {
+		for _, d := range devs {
+			if d.UDN == udn {
+				return zone
+			}
+		}
+	}
+	return ""
+}
+
+// lookup returns the client's current description of the device with the given UDN, or nil.
+// Rediscovery replaces the descriptions, so a Device's own may be out of date.
+// The caller must hold c.mu.
+func (c *Client) lookup(udn string) *goupnp.Device {
+	for _, dev := range c.devices {
+		if dev.UDN == udn {
+			return dev
+		}
+	}
+	return nil
+}
+
+func (c *Client) NumDevices() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.devices)
+}
+
+// Logger returns the Client's logger, as set by WithLogger,
+// for packages built on the Client to log to.
+func (c *Client) Logger() *slog.Logger { return c.logger }
+
+func (c *Client) NumZones() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.zones)
+}
+
+// renameZone moves the devices of a zone to a new zone name.
+func (c *Client) renameZone(oldName, newName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if oldName == newName {
+		return
+	}
+	devs, ok := c.zones[oldName]
+	if !ok {
+		return
+	}
+	delete(c.zones, oldName)
+	c.zones[newName] = append(c.zones[newName], devs...)
 }
 
-func (c *Client) NumDevices() int { return len(c.devices) }
-func (c *Client) NumZones() int   { return len(c.zones) }
+// setIcon records the room icon of a device.
+func (c *Client) setIcon(udn, icon string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if m := c.meta[udn]; m != nil {
+		m.icon = icon
+	}
+}
+
+// refreshZone re-reads the zone name of the device and moves it to that zone.
+func (c *Client) refreshZone(ctx context.Context, d *Device) error {
+	attrs, err := d.ZoneAttributes(ctx)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if m := c.meta[d.dev.UDN]; m != nil {
+		m.icon = attrs.Icon
+	}
+	for zone, devs := range c.zones {
+		for i, dev := range devs {
+			if dev.UDN != d.dev.UDN {
+				continue
+			}
+			if zone == attrs.Name {
+				return nil
+			}
+			devs = append(devs[:i:i], devs[i+1:]...)
+			if len(devs) == 0 {
+				delete(c.zones, zone)
+			} else {
+				c.zones[zone] = devs
+			}
+			break
+		}
+	}
+	if dev := c.lookup(d.dev.UDN); dev != nil {
+		c.zones[attrs.Name] = append(c.zones[attrs.Name], dev)
+	}
+	return nil
+}
 
 type Device struct {
+	c   *Client // may be nil
 	dev *goupnp.Device
 }
 
@@ -79,12 +441,188 @@ func serviceClient(dev *goupnp.Device, serviceType string) (*soap.SOAPClient, er
 	return svcs[0].NewSOAPClient(), nil
 }
 
-func (d *Device) soap(ctx context.Context, serviceType, action string, in, out interface{}) error {
-	sc, err := serviceClient(d.dev, serviceType)
+// Action calls an action on one of the device's services, such as one this package
+// does not wrap. serviceType is one of the service constants, such as AVTransportService.
+// in and out are structs whose fields are the action's arguments, as strings;
+// out must be a pointer. Use &struct{}{} for an action with no results.
+// Action has the same timeout, rate limiting, retries and tracing as the package's own calls.
+// Errors reported by the device are a *UPnPError.
+func (d *Device) Action(ctx context.Context, serviceType, action string, in, out interface{}) error {
+	return d.soap(ctx, serviceType, action, in, out)
+}
+
+func (d *Device) soap(ctx context.Context, serviceType, action string, in, out interface{}) (err error) {
+	var retry RetryPolicy
+	if d.c != nil {
+		retry = d.c.opts.retry
+		if _, ok := ctx.Deadline(); !ok && d.c.opts.actionTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d.c.opts.actionTimeout)
+			defer cancel()
+		}
+		if t := d.c.opts.tracer; t != nil {
+			var done func(error)
+			ctx, done = t.StartAction(ctx, ActionInfo{
+				Device:  d.uid(),
+				Zone:    d.RoomName(),
+				Service: serviceType,
+				Action:  action,
+			})
+			defer func() { done(err) }()
+		}
+		if serviceType == av1.URN_AVTransport_1 && redirectActions[action] && !d.c.opts.noRedirect {
+			defer func() {
+				if ErrorCode(err) == ErrCodeNotCoordinator {
+					err = d.redirect(ctx, action, in, out, err)
+				}
+			}()
+		}
+	}
+	sc, err := d.soapClient(serviceType)
 	if err != nil {
 		return err
 	}
-	return sc.PerformActionCtx(ctx, serviceType, action, in, out)
+	logger := d.logger()
+	lim := d.limiter()
+	for attempt := 1; ; attempt++ {
+		if lim != nil {
+			if err := lim.wait(ctx); err != nil {
+				return err
+			}
+		}
+		logger.DebugContext(ctx, "SOAP request", "device", d.uid(), "service", serviceType, "action", action, "args", in)
+		err = upnpError(serviceType, action, sc.PerformActionCtx(ctx, serviceType, action, in, out))
+		if err == nil {
+			logger.DebugContext(ctx, "SOAP response", "device", d.uid(), "action", action, "result", out)
+			return nil
+		}
+		logger.DebugContext(ctx, "SOAP error", "device", d.uid(), "action", action, "err", err)
+		if !retry.shouldRetry(action, attempt, err) || ctx.Err() != nil {
+			return err
+		}
+		if err := sleepCtx(ctx, retry.backoff(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+// redirectActions are the AVTransport actions that soap redirects to a group's coordinator
+// when a member refuses them. Their arguments mean the same on either device,
+// unlike those of SetAVTransportURI, whose URIs often name the device or its queue,
+// and the queue actions, which act on the device's own queue.
+// Methods that need those on a member find its coordinator first; see Device.coordinator.
+var redirectActions = map[string]bool{
+	"Play":                           true,
+	"Pause":                          true,
+	"Stop":                           true,
+	"Next":                           true,
+	"Previous":                       true,
+	"Seek":                           true,
+	"SetPlayMode":                    true,
+	"SetCrossfadeMode":               true,
+	"ConfigureSleepTimer":            true,
+	"GetRemainingSleepTimerDuration": true,
+}
+
+// redirect performs an AVTransport action on the coordinator of the device's group,
+// after the device refused it with err for not being the coordinator.
+// If the coordinator cannot be found, err is returned.
+func (d *Device) redirect(ctx context.Context, action string, in, out interface{}, err error) error {
+	coord, cerr := d.coordinator(ctx)
+	if cerr != nil || coord.uid() == d.uid() {
+		return err
+	}
+	d.logger().DebugContext(ctx, "Redirecting to coordinator", "device", d.uid(), "coordinator", coord.uid(), "action", action)
+	return coord.soap(ctx, av1.URN_AVTransport_1, action, in, out)
+}
+
+// coordinator returns the coordinator of the device's group, which may be the device itself.
+// What a group plays, including its queue, is chosen on the coordinator.
+// With WithoutCoordinatorRedirect, it is always the device.
+func (d *Device) coordinator(ctx context.Context) (*Device, error) {
+	if d.c == nil || d.c.opts.noRedirect {
+		return d, nil
+	}
+	_, g, err := d.topologyMember(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if g.Coordinator == d.uid() {
+		return d, nil
+	}
+	for _, coord := range d.c.Devices() {
+		if coord.uid() == g.Coordinator {
+			return coord, nil
+		}
+	}
+	return nil, fmt.Errorf("coordinator %s of %s not found", g.Coordinator, d.RoomName())
+}
+
+func (d *Device) logger() *slog.Logger {
+	if d.c == nil {
+		return slog.Default()
+	}
+	return d.c.logger
+}
+
+// soapClient returns a SOAP client for the given service on the device.
+// Devices from a Client share SOAP clients.
+func (d *Device) soapClient(serviceType string) (*soap.SOAPClient, error) {
+	if d.c == nil {
+		sc, err := serviceClient(d.dev, serviceType)
+		if err != nil {
+			return nil, err
+		}
+		sc.HTTPClient = *defaultHTTPClient
+		return sc, nil
+	}
+
+	key := d.dev.UDN + " " + serviceType
+	d.c.mu.Lock()
+	defer d.c.mu.Unlock()
+	if sc, ok := d.c.soapClients[key]; ok {
+		return sc, nil
+	}
+	dev := d.c.lookup(d.dev.UDN)
+	if dev == nil {
+		dev = d.dev // not added yet, or gone
+	}
+	sc, err := serviceClient(dev, serviceType)
+	if err != nil {
+		return nil, err
+	}
+	sc.HTTPClient = *d.c.hc
+	d.c.soapClients[key] = sc
+	return sc, nil
+}
+
+// upnp returns the client's current description of the device, if it has one.
+func (d *Device) upnp() *goupnp.Device {
+	if d.c == nil {
+		return d.dev
+	}
+	d.c.mu.Lock()
+	defer d.c.mu.Unlock()
+	if dev := d.c.lookup(d.dev.UDN); dev != nil {
+		return dev
+	}
+	return d.dev
+}
+
+// httpClient returns the HTTP client to use for non-SOAP requests to the device.
+func (d *Device) httpClient() *http.Client {
+	if d.c == nil {
+		return defaultHTTPClient
+	}
+	return d.c.hc
+}
+
+// boolString returns the UPnP encoding of a boolean.
+func boolString(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
 }
 
 // uid returns the unique ID for the device. It is the identifier starting with "RINCON_".
@@ -93,7 +631,9 @@ func (d *Device) uid() string {
 }
 
 func (c *Client) ZoneDevice(ctx context.Context, zone string) (*Device, error) {
+	c.mu.Lock()
 	devs, ok := c.zones[zone]
+	c.mu.Unlock()
 	if !ok {
 		return nil, fmt.Errorf("unknown zone %q, or it has no devices", zone)
 	}
@@ -106,6 +646,7 @@ func (c *Client) ZoneDevice(ctx context.Context, zone string) (*Device, error) {
 			continue
 		}
 		return &Device{
+			c:   c,
 			dev: dev,
 		}, nil
 	}
@@ -114,20 +655,24 @@ func (c *Client) ZoneDevice(ctx context.Context, zone string) (*Device, error) {
 
 // Join adds the device to a group coordinated by the identified master device.
 func (d *Device) Join(ctx context.Context, master *Device) error {
-	err := d.soap(ctx, av1.URN_AVTransport_1, "SetAVTransportURI", struct {
-		InstanceID         string
-		CurrentURI         string // the destination device
-		CurrentURIMetaData string // left empty
-	}{
-		InstanceID: "0",
-		CurrentURI: "x-rincon:" + master.uid(),
-	}, &struct{}{})
-	if err != nil {
+	if err := d.setAVTransportURI(ctx, "x-rincon:"+master.uid(), ""); err != nil {
 		return fmt.Errorf("joining: %w", err)
 	}
 	return nil
 }
 
+func (d *Device) setAVTransportURI(ctx context.Context, uri, metadata string) error {
+	return d.soap(ctx, av1.URN_AVTransport_1, "SetAVTransportURI", struct {
+		InstanceID         string
+		CurrentURI         string
+		CurrentURIMetaData string // DIDL-Lite XML, or empty
+	}{
+		InstanceID:         "0",
+		CurrentURI:         uri,
+		CurrentURIMetaData: metadata,
+	}, &struct{}{})
+}
+
 func (d *Device) Ungroup(ctx context.Context) error {
 	err := d.soap(ctx, av1.URN_AVTransport_1, "BecomeCoordinatorOfStandaloneGroup", struct {
 		InstanceID string
@@ -168,6 +713,46 @@ var playModeIDs = map[PlayMode]string{
 	ShuffleRepeatOne: "SHUFFLE_REPEAT_ONE",
 }
 
+// MarshalText returns the mode's name as Sonos devices report it, e.g. "SHUFFLE_NOREPEAT".
+func (m PlayMode) MarshalText() ([]byte, error) {
+	id, ok := playModeIDs[m]
+	if !ok {
+		return nil, fmt.Errorf("unknown play mode %d", int(m))
+	}
+	return []byte(id), nil
+}
+
+// UnmarshalText parses a mode's name, as returned by MarshalText.
+func (m *PlayMode) UnmarshalText(text []byte) error {
+	for mode, id := range playModeIDs {
+		if id == string(text) {
+			*m = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown play mode %q", text)
+}
+
+// PlayMode returns the device's play mode.
+func (d *Device) PlayMode(ctx context.Context) (PlayMode, error) {
+	var resp struct {
+		PlayMode       string
+		RecQualityMode string
+	}
+	err := d.soap(ctx, av1.URN_AVTransport_1, "GetTransportSettings", struct {
+		InstanceID string
+	}{InstanceID: "0"}, &resp)
+	if err != nil {
+		return 0, fmt.Errorf("getting play mode: %w", err)
+	}
+	for mode, id := range playModeIDs {
+		if id == resp.PlayMode {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown play mode %q", resp.PlayMode)
+}
+
 func (d *Device) SetPlayMode(ctx context.Context, mode PlayMode) error {
 	err := d.soap(ctx, av1.URN_AVTransport_1, "SetPlayMode", struct {
 		InstanceID  string
@@ -183,15 +768,22 @@ func (d *Device) SetPlayMode(ctx context.Context, mode PlayMode) error {
 }
 
 // SetVolume sets the devices volume, in range [0,100].
+// With WithGroupVolume, the volume of a group coordinator's whole group is set.
 func (d *Device) SetVolume(ctx context.Context, volume int) error {
-	err := d.soap(ctx, "urn:schemas-upnp-org:service:RenderingControl:1", "SetVolume", struct {
+	if d.groupVolumes() {
+		err := d.SetGroupVolume(ctx, volume)
+		if ErrorCode(err) != ErrCodeNotCoordinator {
+			return err
+		}
+	}
+	err := d.soap(ctx, av1.URN_RenderingControl_1, "SetVolume", struct {
 		InstanceID    string
 		Channel       string
 		DesiredVolume string
 	}{
 		InstanceID:    "0",
 		Channel:       "Master",
-		DesiredVolume: strconv.Itoa(volume),
+		DesiredVolume: strconv.Itoa(d.clampVolume(volume)),
 	}, &struct{}{})
 	if err != nil {
 		return fmt.Errorf("setting volume: %w", err)
@@ -199,13 +791,29 @@ func (d *Device) SetVolume(ctx context.Context, volume int) error {
 	return nil
 }
 
+// RampType selects how a device ramps its volume.
+// The ramps differ in speed and shape.
+type RampType string
+
+const (
+	SleepTimerRamp RampType = "SLEEP_TIMER_RAMP_TYPE" // fairly quick, linear
+	AlarmRamp      RampType = "ALARM_RAMP_TYPE"       // slow, from silence
+	AutoplayRamp   RampType = "AUTOPLAY_RAMP_TYPE"    // quick, from silence
+)
+
 // RampVolume smoothly adjusts the device's volume to the target.
 // It returns how long the ramp is expected to take.
 func (d *Device) RampVolume(ctx context.Context, volume int) (time.Duration, error) {
+	return d.RampToVolume(ctx, SleepTimerRamp, volume)
+}
+
+// RampToVolume adjusts the device's volume to the target using the given type of ramp.
+// It returns how long the ramp is expected to take.
+func (d *Device) RampToVolume(ctx context.Context, rt RampType, volume int) (time.Duration, error) {
 	var resp struct {
 		RampTime string // ui4
 	}
-	err := d.soap(ctx, "urn:schemas-upnp-org:service:RenderingControl:1", "RampToVolume", struct {
+	err := d.soap(ctx, av1.URN_RenderingControl_1, "RampToVolume", struct {
 		InstanceID       string
 		Channel          string
 		RampType         string
@@ -215,8 +823,8 @@ func (d *Device) RampVolume(ctx context.Context, volume int) (time.Duration, err
 	}{
 		InstanceID:       "0",
 		Channel:          "Master",
-		RampType:         "SLEEP_TIMER_RAMP_TYPE", // there's also ALARM_RAMP_TYPE and AUTOPLAY_RAMP_TYPE
-		DesiredVolume:    strconv.Itoa(volume),
+		RampType:         string(rt),
+		DesiredVolume:    strconv.Itoa(d.clampVolume(volume)),
 		ResetVolumeAfter: "0", // == false
 	}, &resp)
 	if err != nil {
@@ -229,6 +837,22 @@ func (d *Device) RampVolume(ctx context.Context, volume int) (time.Duration, err
 	return time.Duration(dur) * time.Second, nil // Empirically checked only.
 }
 
+// SleepTimer returns how long until the device's sleep timer stops playback,
+// or zero if it is not set.
+func (d *Device) SleepTimer(ctx context.Context) (time.Duration, error) {
+	var resp struct {
+		RemainingSleepTimerDuration string // "hh:mm:ss" or empty string
+		CurrentSleepTimerGeneration string // ui4
+	}
+	err := d.soap(ctx, av1.URN_AVTransport_1, "GetRemainingSleepTimerDuration", struct {
+		InstanceID string
+	}{InstanceID: "0"}, &resp)
+	if err != nil {
+		return 0, fmt.Errorf("getting sleep timer: %w", err)
+	}
+	return parseHMS(resp.RemainingSleepTimerDuration), nil
+}
+
 func (d *Device) SetSleepTimer(ctx context.Context, duration time.Duration) error {
 	var dur string
 	if duration > 0 {
@@ -279,72 +903,84 @@ func (d *Device) Stop(ctx context.Context) error {
 	return nil
 }
 
-func (d *Device) LoadSonosPlaylist(ctx context.Context, playlistName string) error {
-	var raw struct {
-		Result string // DIDL-Lite XML
-	}
-	err := d.soap(ctx, av1.URN_ContentDirectory_1, "Browse", struct {
-		ObjectID       string
-		BrowseFlag     string
-		Filter         string
-		StartingIndex  string
-		RequestedCount string
-		SortCriteria   string
+func (d *Device) Pause(ctx context.Context) error {
+	err := d.soap(ctx, av1.URN_AVTransport_1, "Pause", struct {
+		InstanceID string
 	}{
-		ObjectID:       "SQ:", // TODO: add a search query
-		BrowseFlag:     "BrowseDirectChildren",
-		Filter:         "*", // all fields
-		StartingIndex:  "0",
-		RequestedCount: "100",
-		SortCriteria:   "+upnp:artist,+dc:title",
-	}, &raw)
+		InstanceID: "0",
+	}, &struct{}{})
 	if err != nil {
-		return fmt.Errorf("browsing: %w", err)
+		return fmt.Errorf("pausing: %w", err)
 	}
+	return nil
+}
 
-	var didl struct {
-		Container []struct {
-			ID    string `xml:"id,attr"`
-			Title string `xml:"title"`
-			Res   string `xml:"res"`
-		} `xml:"container"`
+// PlayLineIn starts playing the analog line-in of the source device.
+// The source may be the same device, or any other device with a line-in
+// (e.g. a Play:5, Amp or Port).
+func (d *Device) PlayLineIn(ctx context.Context, source *Device) error {
+	coord, err := d.coordinator(ctx)
+	if err != nil {
+		return err
 	}
-	if xml.Unmarshal([]byte(raw.Result), &didl); err != nil {
-		return fmt.Errorf("unmarshaling DIDL-Lite XML: %w", err)
+	if err := coord.setAVTransportURI(ctx, "x-rincon-stream:"+source.uid(), ""); err != nil {
+		return fmt.Errorf("selecting line-in: %w", err)
 	}
+	return coord.Play(ctx)
+}
 
-	var uri string
-	for _, c := range didl.Container {
-		if c.Title == playlistName {
-			uri = c.Res
-			break
-		}
+// PlayTV switches a home theater device (e.g. a Beam or Arc) to its TV input.
+// If the device is grouped, the whole group plays it.
+func (d *Device) PlayTV(ctx context.Context) error {
+	coord, err := d.coordinator(ctx)
+	if err != nil {
+		return err
+	}
+	if err := coord.setAVTransportURI(ctx, "x-sonos-htastream:"+d.uid()+":spdif", ""); err != nil {
+		return fmt.Errorf("selecting TV input: %w", err)
 	}
-	if uri == "" {
-		return fmt.Errorf("did not find Sonos playlist named %q (checked %d)", playlistName, len(didl.Container))
+	return coord.Play(ctx)
+}
+
+// EnqueueResult reports the outcome of adding to a queue.
+type EnqueueResult struct {
+	FirstTrack  int // 1-based queue position of the first track added
+	TracksAdded int
+	QueueLength int // length of the queue afterwards
+}
+
+// LoadSonosPlaylist adds the named Sonos playlist to the device's queue.
+// The name is matched as MatchSonosPlaylist does.
+func (d *Device) LoadSonosPlaylist(ctx context.Context, playlistName string, match PlaylistMatch, opts EnqueueOptions) (EnqueueResult, error) {
+	pl, err := d.MatchSonosPlaylist(ctx, playlistName, match)
+	if err != nil {
+		return EnqueueResult{}, err
 	}
 
-	// Add the playlist.
-	var resp struct {
-		NumTracksAdded string
-		NewQueueLength string
-	}
-	err = d.soap(ctx, av1.URN_AVTransport_1, "AddURIToQueue", struct {
-		InstanceID                      string
-		EnqueuedURI                     string
-		EnqueuedURIMetaData             string
-		DesiredFirstTrackNumberEnqueued string
-		EnqueueAsNext                   string
-	}{
-		InstanceID:                      "0",
-		EnqueuedURI:                     uri,
-		DesiredFirstTrackNumberEnqueued: "1", // add to end
-		EnqueueAsNext:                   "1",
-	}, &resp)
+	res, err := d.addURIToQueue(ctx, pl.URI, "", opts)
 	if err != nil {
-		return fmt.Errorf("adding to queue: %w", err)
+		return EnqueueResult{}, err
 	}
-	_ = resp // TODO: report stats
+	return res, nil
+}
 
-	return nil
+// parseEnqueueResult parses the result of AddURIToQueue.
+func parseEnqueueResult(first, added, length string) (EnqueueResult, error) {
+	var res EnqueueResult
+	for _, f := range []struct {
+		name string
+		s    string
+		p    *int
+	}{
+		{"first track number", first, &res.FirstTrack},
+		{"number of tracks added", added, &res.TracksAdded},
+		{"new queue length", length, &res.QueueLength},
+	} {
+		n, err := strconv.Atoi(f.s)
+		if err != nil {
+			return EnqueueResult{}, fmt.Errorf("parsing %s %q: %w", f.name, f.s, err)
+		}
+		*f.p = n
+	}
+	return res, nil
 }
@@ -0,0 +1,274 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dsymonds/sonos/sonostest"
+)
+
+// newTestClient returns a Client with the fake devices added.
+func newTestClient(t *testing.T, opts []Option, fakes ...*sonostest.Device) *Client {
+	t.Helper()
+	ctx := context.Background()
+	c, err := NewClientFromIPs(ctx, nil, opts...)
+	if err != nil {
+		t.Fatalf("NewClientFromIPs: %v", err)
+	}
+	for _, fake := range fakes {
+		if err := c.AddDeviceByURL(ctx, fake.Location()); err != nil {
+			t.Fatalf("AddDeviceByURL(%s): %v", fake.Location(), err)
+		}
+	}
+	return c
+}
+
+// newFake starts a fake device, closing it when the test finishes.
+func newFake(t *testing.T, zone string) *sonostest.Device {
+	fake := sonostest.NewDevice(zone)
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+// device returns the client's device for the fake.
+func device(t *testing.T, c *Client, fake *sonostest.Device) *Device {
+	t.Helper()
+	for _, d := range c.Devices() {
+		if d.UUID() == fake.UUID {
+			return d
+		}
+	}
+	t.Fatalf("device %s not in client", fake.UUID)
+	return nil
+}
+
+// actions returns the names of the actions that the fake received.
+func actions(fake *sonostest.Device) []string {
+	var names []string
+	for _, call := range fake.Calls() {
+		names = append(names, call.Action)
+	}
+	return names
+}
+
+func TestAddDeviceByURL(t *testing.T) {
+	ctx := context.Background()
+	kitchen := newFake(t, "Kitchen")
+	den := newFake(t, "Den")
+	c := newTestClient(t, nil, kitchen, den)
+
+	// Adding a device again does nothing.
+	if err := c.AddDeviceByURL(ctx, kitchen.Location()); err != nil {
+		t.Fatalf("AddDeviceByURL again: %v", err)
+	}
+	if n := c.NumDevices(); n != 2 {
+		t.Errorf("NumDevices = %d, want 2", n)
+	}
+	if n := c.NumZones(); n != 2 {
+		t.Errorf("NumZones = %d, want 2", n)
+	}
+	d, err := c.ZoneDevice(ctx, "Kitchen")
+	if err != nil {
+		t.Fatalf("ZoneDevice: %v", err)
+	}
+	if d.UUID() != kitchen.UUID {
+		t.Errorf("Kitchen device is %s, want %s", d.UUID(), kitchen.UUID)
+	}
+	if got := d.RoomName(); got != "Kitchen" {
+		t.Errorf("RoomName = %q, want %q", got, "Kitchen")
+	}
+	if _, err := c.ZoneDevice(ctx, "Attic"); err == nil {
+		t.Errorf("ZoneDevice for unknown zone succeeded")
+	}
+}
+
+func TestAddDeviceByURLNotSonos(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0"><specVersion><major>1</major><minor>0</minor></specVersion>
+<device><deviceType>urn:schemas-upnp-org:device:MediaRenderer:1</deviceType><manufacturer>Acme</manufacturer><UDN>uuid:acme-1</UDN></device></root>`)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, nil)
+	if err := c.AddDeviceByURL(context.Background(), srv.URL+"/description.xml"); !errors.Is(err, errNotSonos) {
+		t.Errorf("AddDeviceByURL = %v, want %v", err, errNotSonos)
+	}
+	if n := c.NumDevices(); n != 0 {
+		t.Errorf("NumDevices = %d, want 0", n)
+	}
+}
+
+func TestAddDeviceByURLRenamed(t *testing.T) {
+	// The zone name comes from the device, not its description.
+	fake := newFake(t, "Kitchen")
+	c := newTestClient(t, nil)
+	fake.Handle("GetZoneAttributes", func(map[string]string) (map[string]string, error) {
+		return map[string]string{"CurrentZoneName": "Scullery", "CurrentIcon": "x-rincon-roomicon:kitchen"}, nil
+	})
+	if err := c.AddDeviceByURL(context.Background(), fake.Location()); err != nil {
+		t.Fatalf("AddDeviceByURL: %v", err)
+	}
+	if got := device(t, c, fake).RoomName(); got != "Scullery" {
+		t.Errorf("RoomName = %q, want %q", got, "Scullery")
+	}
+}
+
+func TestLoadSonosPlaylist(t *testing.T) {
+	ctx := context.Background()
+	fake := newFake(t, "Kitchen")
+	fake.AddPlaylist("Dinner")
+	fake.AddPlaylist("Road Trip",
+		sonostest.Track{URI: "x-file-cifs://nas/1.mp3"},
+		sonostest.Track{URI: "x-file-cifs://nas/2.mp3"},
+		sonostest.Track{URI: "x-file-cifs://nas/3.mp3"},
+	)
+	fake.SetQueue(sonostest.Track{URI: "x-file-cifs://nas/0.mp3"})
+	c := newTestClient(t, nil, fake)
+	d := device(t, c, fake)
+
+	res, err := d.LoadSonosPlaylist(ctx, "road", MatchPrefix, EnqueueOptions{})
+	if err != nil {
+		t.Fatalf("LoadSonosPlaylist: %v", err)
+	}
+	if want := (EnqueueResult{FirstTrack: 2, TracksAdded: 3, QueueLength: 4}); res != want {
+		t.Errorf("LoadSonosPlaylist = %+v, want %+v", res, want)
+	}
+
+	res, err = d.LoadSonosPlaylist(ctx, "Road Trip", MatchExact, EnqueueOptions{Position: 1})
+	if err != nil {
+		t.Fatalf("LoadSonosPlaylist at start: %v", err)
+	}
+	if want := (EnqueueResult{FirstTrack: 1, TracksAdded: 3, QueueLength: 7}); res != want {
+		t.Errorf("LoadSonosPlaylist at start = %+v, want %+v", res, want)
+	}
+
+	_, err = d.LoadSonosPlaylist(ctx, "Dinnr", MatchExact, EnqueueOptions{})
+	var nf *PlaylistNotFoundError
+	if !errors.As(err, &nf) {
+		t.Fatalf("LoadSonosPlaylist of missing playlist = %v, want a *PlaylistNotFoundError", err)
+	}
+	if len(nf.Suggestions) == 0 || nf.Suggestions[0] != "Dinner" {
+		t.Errorf("suggestions = %q, want Dinner first", nf.Suggestions)
+	}
+}
+
+func TestParseEnqueueResult(t *testing.T) {
+	tests := []struct {
+		first, added, length string
+		want                 EnqueueResult
+		wantErr              bool
+	}{
+		{"1", "1", "1", EnqueueResult{FirstTrack: 1, TracksAdded: 1, QueueLength: 1}, false},
+		{"5", "12", "16", EnqueueResult{FirstTrack: 5, TracksAdded: 12, QueueLength: 16}, false},
+		{"0", "0", "3", EnqueueResult{QueueLength: 3}, false},
+		{"", "1", "1", EnqueueResult{}, true},
+		{"1", "x", "1", EnqueueResult{}, true},
+		{"1", "1", "-", EnqueueResult{}, true},
+	}
+	for _, tc := range tests {
+		got, err := parseEnqueueResult(tc.first, tc.added, tc.length)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("parseEnqueueResult(%q, %q, %q) error = %v, want error %t", tc.first, tc.added, tc.length, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("parseEnqueueResult(%q, %q, %q) = %+v, want %+v", tc.first, tc.added, tc.length, got, tc.want)
+		}
+	}
+}
+
+func TestCoordinatorRedirect(t *testing.T) {
+	ctx := context.Background()
+	lead := newFake(t, "Living Room")
+	member := newFake(t, "Kitchen")
+	sonostest.Group(lead, member)
+	c := newTestClient(t, nil, lead, member)
+
+	if err := device(t, c, member).Play(ctx); err != nil {
+		t.Fatalf("Play on member: %v", err)
+	}
+	if got := lead.State("TransportState"); got != "PLAYING" {
+		t.Errorf("coordinator transport state = %q, want PLAYING", got)
+	}
+	if got := member.State("TransportState"); got != "STOPPED" {
+		t.Errorf("member transport state = %q, want STOPPED", got)
+	}
+
+	// Volume is the member's own, so it is not redirected.
+	if err := device(t, c, member).SetVolume(ctx, 35); err != nil {
+		t.Fatalf("SetVolume on member: %v", err)
+	}
+	if got := member.State("Volume"); got != "35" {
+		t.Errorf("member volume = %s, want 35", got)
+	}
+	if got := lead.State("Volume"); got != "20" {
+		t.Errorf("coordinator volume = %s, want 20", got)
+	}
+}
+
+func TestCoordinatorRedirectQueue(t *testing.T) {
+	// Playing from the queue selects the coordinator's queue, on the coordinator.
+	ctx := context.Background()
+	lead := newFake(t, "Living Room")
+	member := newFake(t, "Kitchen")
+	sonostest.Group(lead, member)
+	lead.SetQueue(sonostest.Track{URI: "x-file-cifs://nas/a.mp3", Title: "A"}, sonostest.Track{URI: "x-file-cifs://nas/b.mp3", Title: "B"})
+	c := newTestClient(t, nil, lead, member)
+
+	n, err := device(t, c, member).PlayQueueTrack(ctx, MatchTitleOrCreator("b"))
+	if err != nil {
+		t.Fatalf("PlayQueueTrack on member: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("PlayQueueTrack = %d, want 2", n)
+	}
+	if got, want := lead.State("URI"), "x-rincon-queue:"+lead.UUID+"#0"; got != want {
+		t.Errorf("coordinator URI = %q, want %q", got, want)
+	}
+	if got := member.State("URI"); got != "" {
+		t.Errorf("member URI = %q, want it unchanged", got)
+	}
+}
+
+func TestWithoutCoordinatorRedirect(t *testing.T) {
+	lead := newFake(t, "Living Room")
+	member := newFake(t, "Kitchen")
+	sonostest.Group(lead, member)
+	c := newTestClient(t, []Option{WithoutCoordinatorRedirect()}, lead, member)
+
+	err := device(t, c, member).Play(context.Background())
+	if code := ErrorCode(err); code != ErrCodeNotCoordinator {
+		t.Errorf("Play on member = %v, want error %d", err, ErrCodeNotCoordinator)
+	}
+	for _, a := range actions(lead) {
+		if a == "Play" {
+			t.Errorf("coordinator received Play")
+		}
+	}
+}
+
+func TestPlayTVGrouped(t *testing.T) {
+	ctx := context.Background()
+	lead := newFake(t, "Kitchen")
+	tv := newFake(t, "Living Room")
+	sonostest.Group(lead, tv)
+	c := newTestClient(t, nil, lead, tv)
+
+	if err := device(t, c, tv).PlayTV(ctx); err != nil {
+		t.Fatalf("PlayTV: %v", err)
+	}
+	if got, want := lead.State("URI"), "x-sonos-htastream:"+tv.UUID+":spdif"; got != want {
+		t.Errorf("coordinator URI = %q, want %q", got, want)
+	}
+	if got := lead.State("TransportState"); got != "PLAYING" {
+		t.Errorf("coordinator transport state = %q, want PLAYING", got)
+	}
+	if got := tv.State("URI"); got != "" {
+		t.Errorf("member URI = %q, want it unchanged", got)
+	}
+}
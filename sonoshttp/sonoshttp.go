@@ -0,0 +1,193 @@
+// Package sonoshttp provides a JSON HTTP API for controlling a Sonos system.
+//
+//	http.Handle("/sonos/", http.StripPrefix("/sonos", sonoshttp.Handler(client)))
+//
+// The endpoints are:
+//
+//	GET    /zones               list the zones
+//	GET    /zones/Z             get the state of zone Z
+//	POST   /zones/Z/play        start playback
+//	POST   /zones/Z/pause       pause playback
+//	POST   /zones/Z/stop        stop playback
+//	GET    /zones/Z/volume      get the volume, as {"volume": N}
+//	PUT    /zones/Z/volume      set the volume, from {"volume": N}
+//	GET    /zones/Z/queue       list the queue
+//	DELETE /zones/Z/queue       clear the queue
+//	POST   /zones/Z/join        join the group of another zone, from {"zone": "Kitchen"}
+//	POST   /zones/Z/ungroup     leave the zone's group
+//
+// Zone names must be path escaped. Errors are reported as {"error": "..."}.
+package sonoshttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dsymonds/sonos"
+)
+
+// Handler returns an HTTP handler serving the API for the client.
+func Handler(c *sonos.Client) http.Handler {
+	return &handler{c: c}
+}
+
+type handler struct {
+	c *sonos.Client
+}
+
+// httpError is an error with a particular HTTP status.
+type httpError struct {
+	code int
+	msg  string
+}
+
+func (he httpError) Error() string { return he.msg }
+
+func errorf(code int, format string, args ...interface{}) error {
+	return httpError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+// ZoneState is the state of a zone, as returned by GET /zones/Z.
+type ZoneState struct {
+	Zone   string `json:"zone"`
+	State  string `json:"state"`
+	Volume int    `json:"volume"`
+	Muted  bool   `json:"muted"`
+	Track  int    `json:"track,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Artist string `json:"artist,omitempty"`
+	Album  string `json:"album,omitempty"`
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.serve(r)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if he, ok := err.(httpError); ok {
+			code = he.code
+		}
+		writeJSON(w, code, map[string]string{"error": err.Error()})
+		return
+	}
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (h *handler) serve(r *http.Request) (interface{}, error) {
+	ctx := r.Context()
+	var parts []string
+	for _, p := range strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/") {
+		up, err := url.PathUnescape(p)
+		if err != nil {
+			return nil, errorf(http.StatusBadRequest, "bad path: %v", err)
+		}
+		parts = append(parts, up)
+	}
+	if len(parts) == 0 || parts[0] != "zones" {
+		return nil, errorf(http.StatusNotFound, "not found")
+	}
+	if len(parts) == 1 {
+		if r.Method != "GET" {
+			return nil, errorf(http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return h.c.Zones(ctx)
+	}
+
+	zone := parts[1]
+	dev, err := h.c.ZoneDevice(ctx, zone)
+	if err != nil {
+		return nil, errorf(http.StatusNotFound, "%v", err)
+	}
+	var what string
+	if len(parts) > 2 {
+		what = parts[2]
+	}
+	if len(parts) > 3 {
+		return nil, errorf(http.StatusNotFound, "not found")
+	}
+
+	switch r.Method + " " + what {
+	case "GET ":
+		return zoneState(ctx, zone, dev)
+	case "POST play":
+		return nil, dev.Play(ctx)
+	case "POST pause":
+		return nil, dev.Pause(ctx)
+	case "POST stop":
+		return nil, dev.Stop(ctx)
+	case "GET volume":
+		vol, err := dev.Volume(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"volume": vol}, nil
+	case "PUT volume", "POST volume":
+		var req struct {
+			Volume *int `json:"volume"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Volume == nil {
+			return nil, errorf(http.StatusBadRequest, `want {"volume": N}`)
+		}
+		return nil, dev.SetVolume(ctx, *req.Volume)
+	case "GET queue":
+		items, err := dev.Queue(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if items == nil {
+			items = []sonos.QueueItem{}
+		}
+		return items, nil
+	case "DELETE queue":
+		return nil, dev.ClearQueue(ctx)
+	case "POST join":
+		var req struct {
+			Zone string `json:"zone"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Zone == "" {
+			return nil, errorf(http.StatusBadRequest, `want {"zone": "..."}`)
+		}
+		master, err := h.c.ZoneDevice(ctx, req.Zone)
+		if err != nil {
+			return nil, errorf(http.StatusNotFound, "%v", err)
+		}
+		return nil, dev.Join(ctx, master)
+	case "POST ungroup":
+		return nil, dev.Ungroup(ctx)
+	}
+	return nil, errorf(http.StatusNotFound, "not found")
+}
+
+func zoneState(ctx context.Context, zone string, dev *sonos.Device) (*ZoneState, error) {
+	zs := &ZoneState{Zone: zone}
+	state, err := dev.TransportState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	zs.State = string(state)
+	if zs.Volume, err = dev.Volume(ctx); err != nil {
+		return nil, err
+	}
+	if zs.Muted, err = dev.Mute(ctx); err != nil {
+		return nil, err
+	}
+	pi, err := dev.PositionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	zs.Track, zs.Title, zs.Artist, zs.Album = pi.Track, pi.Title, pi.Creator, pi.Album
+	return zs, nil
+}
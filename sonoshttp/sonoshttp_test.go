@@ -0,0 +1,97 @@
+package sonoshttp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dsymonds/sonos"
+	"github.com/dsymonds/sonos/sonostest"
+)
+
+func TestHandler(t *testing.T) {
+	ctx := context.Background()
+	fake := sonostest.NewDevice("Living Room")
+	defer fake.Close()
+	fake.SetQueue(sonostest.Track{URI: "x-file-cifs://nas/a.mp3", Title: "A", Creator: "Artist"})
+	c, err := sonos.NewClientFromIPs(ctx, nil)
+	if err != nil {
+		t.Fatalf("NewClientFromIPs: %v", err)
+	}
+	if err := c.AddDeviceByURL(ctx, fake.Location()); err != nil {
+		t.Fatalf("AddDeviceByURL: %v", err)
+	}
+	srv := httptest.NewServer(Handler(c))
+	defer srv.Close()
+
+	do := func(method, path, body string, wantCode int) string {
+		t.Helper()
+		req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != wantCode {
+			t.Errorf("%s %s: got HTTP %d %s, want %d", method, path, resp.StatusCode, b, wantCode)
+		}
+		return string(b)
+	}
+
+	var zones []sonos.Zone
+	if err := json.Unmarshal([]byte(do("GET", "/zones", "", http.StatusOK)), &zones); err != nil {
+		t.Fatalf("bad zones: %v", err)
+	}
+	if len(zones) != 1 || zones[0].Name != "Living Room" {
+		t.Errorf("zones are %+v, want just Living Room", zones)
+	}
+
+	do("POST", "/zones/Living%20Room/play", "", http.StatusNoContent)
+	if got := fake.State("TransportState"); got != "PLAYING" {
+		t.Errorf("after play, transport state is %q, want PLAYING", got)
+	}
+	do("PUT", "/zones/Living%20Room/volume", `{"volume": 42}`, http.StatusNoContent)
+	if got := fake.State("Volume"); got != "42" {
+		t.Errorf("after setting volume, it is %s, want 42", got)
+	}
+	if got := do("GET", "/zones/Living%20Room/volume", "", http.StatusOK); strings.TrimSpace(got) != `{"volume":42}` {
+		t.Errorf("volume is %s", got)
+	}
+	do("PUT", "/zones/Living%20Room/volume", `{}`, http.StatusBadRequest)
+
+	var zs ZoneState
+	if err := json.Unmarshal([]byte(do("GET", "/zones/Living%20Room", "", http.StatusOK)), &zs); err != nil {
+		t.Fatalf("bad zone state: %v", err)
+	}
+	if want := (ZoneState{Zone: "Living Room", State: "PLAYING", Volume: 42}); zs != want {
+		t.Errorf("zone state is %+v, want %+v", zs, want)
+	}
+
+	var items []sonos.QueueItem
+	if err := json.Unmarshal([]byte(do("GET", "/zones/Living%20Room/queue", "", http.StatusOK)), &items); err != nil {
+		t.Fatalf("bad queue: %v", err)
+	}
+	if len(items) != 1 || items[0].Title != "A" || items[0].Creator != "Artist" {
+		t.Errorf("queue is %+v", items)
+	}
+	do("DELETE", "/zones/Living%20Room/queue", "", http.StatusNoContent)
+	if n := len(fake.Queue()); n != 0 {
+		t.Errorf("after clearing, queue has %d tracks", n)
+	}
+	if got := do("GET", "/zones/Living%20Room/queue", "", http.StatusOK); strings.TrimSpace(got) != "[]" {
+		t.Errorf("empty queue is %s, want []", got)
+	}
+
+	do("GET", "/zones/Attic", "", http.StatusNotFound)
+	do("GET", "/zones/Living%20Room/bogus", "", http.StatusNotFound)
+	do("POST", "/zones", "", http.StatusMethodNotAllowed)
+	do("GET", "/other", "", http.StatusNotFound)
+}
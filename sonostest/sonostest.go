@@ -0,0 +1,544 @@
+// Package sonostest provides a fake Sonos device for testing.
+//
+// A Device serves a device description and answers the SOAP actions
+// that package sonos uses, recording each call. Typical use is
+//
+//	fake := sonostest.NewDevice("Kitchen")
+//	defer fake.Close()
+//	c, err := sonos.NewClientFromIPs(ctx, nil)
+//	...
+//	err = c.AddDeviceByURL(ctx, fake.Location())
+//
+// Simple state (volume, which doubles as the group volume, mute, transport state, AVTransport URI) is tracked,
+// so that a get reflects a preceding set, as are the queue and any playlists added with AddPlaylist.
+// Devices can be grouped with Group. Any action can be overridden with Handle.
+package sonostest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Call is a SOAP action received by a Device.
+type Call struct {
+	Service string // e.g. "urn:schemas-upnp-org:service:AVTransport:1"
+	Action  string // e.g. "Play"
+	Args    map[string]string
+}
+
+// A HandlerFunc answers a SOAP action. It returns the response arguments.
+// To report a UPnP error, return a Fault.
+type HandlerFunc func(args map[string]string) (map[string]string, error)
+
+// Fault is a UPnP error code, which a HandlerFunc may return as an error.
+type Fault int
+
+func (f Fault) Error() string { return fmt.Sprintf("UPnP error %d", int(f)) }
+
+// Track is a track in a Device's queue or playlists.
+type Track struct {
+	URI     string
+	Title   string
+	Creator string
+}
+
+// Device is a fake Sonos device.
+type Device struct {
+	UUID     string // e.g. "RINCON_000E58000001"
+	ZoneName string
+
+	server *httptest.Server
+
+	mu        sync.Mutex
+	calls     []Call
+	handlers  map[string]HandlerFunc // by action
+	state     map[string]string
+	queue     []Track
+	playlists []playlist
+	group     []*Device // coordinator first, or nil if ungrouped
+}
+
+type playlist struct {
+	title  string
+	tracks []Track
+}
+
+var nextID struct {
+	sync.Mutex
+	n int
+}
+
+// NewDevice starts a fake device in the named zone.
+// The caller should call Close when finished.
+func NewDevice(zone string) *Device {
+	nextID.Lock()
+	nextID.n++
+	id := nextID.n
+	nextID.Unlock()
+
+	d := &Device{
+		UUID:     fmt.Sprintf("RINCON_000E58%06d01400", id),
+		ZoneName: zone,
+		handlers: make(map[string]HandlerFunc),
+		state: map[string]string{
+			"Volume":         "20",
+			"Mute":           "0",
+			"TransportState": "STOPPED",
+			"PlayMode":       "NORMAL",
+			"URI":            "",
+			"URIMetaData":    "",
+		},
+	}
+	d.server = httptest.NewServer(http.HandlerFunc(d.serveHTTP))
+	return d
+}
+
+// Close shuts down the device's server.
+func (d *Device) Close() { d.server.Close() }
+
+// Location returns the URL of the device description.
+func (d *Device) Location() string { return d.server.URL + "/xml/device_description.xml" }
+
+// Calls returns the SOAP actions received so far, in order.
+func (d *Device) Calls() []Call {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Call(nil), d.calls...)
+}
+
+// Handle overrides how the device answers the named action.
+func (d *Device) Handle(action string, f HandlerFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[action] = f
+}
+
+// State returns a piece of the device's simple state:
+// "Volume", "Mute", "TransportState", "PlayMode", "URI" or "URIMetaData".
+func (d *Device) State(key string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.state[key]
+}
+
+// SetQueue replaces the tracks in the device's queue.
+func (d *Device) SetQueue(tracks ...Track) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queue = append([]Track(nil), tracks...)
+}
+
+// Queue returns the tracks in the device's queue.
+func (d *Device) Queue() []Track {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Track(nil), d.queue...)
+}
+
+// AddPlaylist adds a Sonos playlist to the device, returning its ID (e.g. "SQ:0").
+func (d *Device) AddPlaylist(title string, tracks ...Track) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.playlists = append(d.playlists, playlist{title: title, tracks: append([]Track(nil), tracks...)})
+	return fmt.Sprintf("SQ:%d", len(d.playlists)-1)
+}
+
+// Group puts the devices in a group coordinated by coord, as they report in their topology.
+// The members refuse transport actions, such as Play or Seek, with error 800
+// (not the coordinator), as real group members do.
+func Group(coord *Device, members ...*Device) {
+	group := append([]*Device{coord}, members...)
+	for _, d := range group {
+		d.mu.Lock()
+		d.group = group
+		d.mu.Unlock()
+	}
+}
+
+// memberActions are the actions that group members refuse.
+var memberActions = map[string]bool{
+	"Play":                true,
+	"Pause":               true,
+	"Stop":                true,
+	"Next":                true,
+	"Previous":            true,
+	"Seek":                true,
+	"SetPlayMode":         true,
+	"ConfigureSleepTimer": true,
+}
+
+func (d *Device) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/xml/device_description.xml" {
+		w.Header().Set("Content-Type", "text/xml")
+		fmt.Fprintf(w, descriptionTemplate, xmlEscape(d.ZoneName), d.UUID)
+		return
+	}
+	if r.Method != "POST" || !strings.HasSuffix(r.URL.Path, "/Control") {
+		http.NotFound(w, r)
+		return
+	}
+
+	service, action, _ := strings.Cut(strings.Trim(r.Header.Get("SOAPACTION"), `"`), "#")
+	args, err := parseArgs(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	d.mu.Lock()
+	d.calls = append(d.calls, Call{Service: service, Action: action, Args: args})
+	h, ok := d.handlers[action]
+	d.mu.Unlock()
+	if !ok && action == "GetZoneGroupState" {
+		h = d.zoneGroupState
+	} else if !ok {
+		h = func(args map[string]string) (map[string]string, error) {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			return d.defaultAction(action, args)
+		}
+	}
+	out, err := h(args)
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header + `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`)
+	if f, ok := err.(Fault); ok {
+		fmt.Fprintf(&buf, `<s:Fault><faultcode>s:Client</faultcode><faultstring>UPnPError</faultstring><detail><UPnPError xmlns="urn:schemas-upnp-org:control-1-0"><errorCode>%d</errorCode></UPnPError></detail></s:Fault>`, int(f))
+		buf.WriteString(`</s:Body></s:Envelope>`)
+		w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(buf.Bytes())
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(&buf, `<u:%sResponse xmlns:u="%s">`, action, service)
+	for k, v := range out {
+		fmt.Fprintf(&buf, "<%s>%s</%s>", k, xmlEscape(v), k)
+	}
+	fmt.Fprintf(&buf, `</u:%sResponse></s:Body></s:Envelope>`, action)
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	w.Write(buf.Bytes())
+}
+
+// defaultAction answers an action that has no handler. d.mu must be held.
+func (d *Device) defaultAction(action string, args map[string]string) (map[string]string, error) {
+	if memberActions[action] && len(d.group) > 0 && d.group[0] != d {
+		return nil, Fault(800)
+	}
+	switch action {
+	case "GetZoneAttributes":
+		return map[string]string{
+			"CurrentZoneName":      d.ZoneName,
+			"CurrentIcon":          "x-rincon-roomicon:living",
+			"CurrentConfiguration": "1",
+		}, nil
+	case "SetZoneAttributes":
+		d.ZoneName = args["DesiredZoneName"]
+	case "GetHouseholdID":
+		return map[string]string{"CurrentHouseholdID": "Sonos_fakehousehold"}, nil
+	case "GetZoneInfo":
+		return map[string]string{
+			"SerialNumber":           "00-0E-58-00-00-01:A",
+			"SoftwareVersion":        "79.1-56030",
+			"DisplaySoftwareVersion": "16.3",
+			"HardwareVersion":        "1.20.1.6-2",
+			"IPAddress":              "127.0.0.1",
+			"MACAddress":             "00:0E:58:00:00:01",
+		}, nil
+	case "GetVolume":
+		return map[string]string{"CurrentVolume": d.state["Volume"]}, nil
+	case "SetVolume":
+		d.state["Volume"] = args["DesiredVolume"]
+	case "GetGroupVolume":
+		return map[string]string{"CurrentVolume": d.state["Volume"]}, nil
+	case "SetGroupVolume":
+		d.state["Volume"] = args["DesiredVolume"]
+	case "SetRelativeGroupVolume":
+		vol, _ := strconv.Atoi(d.state["Volume"])
+		adj, _ := strconv.Atoi(args["Adjustment"])
+		vol = max(0, min(vol+adj, 100))
+		d.state["Volume"] = strconv.Itoa(vol)
+		return map[string]string{"NewVolume": d.state["Volume"]}, nil
+	case "GetMute":
+		return map[string]string{"CurrentMute": d.state["Mute"]}, nil
+	case "SetMute":
+		d.state["Mute"] = args["DesiredMute"]
+	case "GetTransportInfo":
+		return map[string]string{
+			"CurrentTransportState":  d.state["TransportState"],
+			"CurrentTransportStatus": "OK",
+			"CurrentSpeed":           "1",
+		}, nil
+	case "Play":
+		d.state["TransportState"] = "PLAYING"
+	case "Pause":
+		d.state["TransportState"] = "PAUSED_PLAYBACK"
+	case "Stop":
+		d.state["TransportState"] = "STOPPED"
+	case "GetTransportSettings":
+		return map[string]string{
+			"PlayMode":       d.state["PlayMode"],
+			"RecQualityMode": "NOT_IMPLEMENTED",
+		}, nil
+	case "SetPlayMode":
+		d.state["PlayMode"] = args["NewPlayMode"]
+	case "ConfigureSleepTimer":
+		d.state["SleepTimer"] = args["NewSleepTimerDuration"]
+	case "GetRemainingSleepTimerDuration":
+		return map[string]string{
+			"RemainingSleepTimerDuration": d.state["SleepTimer"],
+			"CurrentSleepTimerGeneration": "0",
+		}, nil
+	case "SetAVTransportURI":
+		d.state["URI"] = args["CurrentURI"]
+		d.state["URIMetaData"] = args["CurrentURIMetaData"]
+	case "GetPositionInfo":
+		return map[string]string{
+			"Track":         "0",
+			"TrackDuration": "0:00:00",
+			"TrackMetaData": "",
+			"TrackURI":      d.state["URI"],
+			"RelTime":       "0:00:00",
+		}, nil
+	case "GetMediaInfo":
+		return map[string]string{
+			"NrTracks":           "0",
+			"CurrentURI":         d.state["URI"],
+			"CurrentURIMetaData": d.state["URIMetaData"],
+		}, nil
+	case "Browse":
+		return d.browse(args)
+	case "GetSystemUpdateID":
+		return map[string]string{"Id": "1"}, nil
+	case "GetShareIndexInProgress":
+		return map[string]string{"IsIndexing": "0"}, nil
+	case "AddURIToQueue":
+		tracks := []Track{{URI: args["EnqueuedURI"]}}
+		for i, pl := range d.playlists {
+			if args["EnqueuedURI"] == playlistURI(i) {
+				tracks = pl.tracks
+			}
+		}
+		return d.enqueue(tracks, args["DesiredFirstTrackNumberEnqueued"]), nil
+	case "AddMultipleURIsToQueue":
+		var tracks []Track
+		for _, uri := range strings.Fields(args["EnqueuedURIs"]) {
+			tracks = append(tracks, Track{URI: uri})
+		}
+		return d.enqueue(tracks, args["DesiredFirstTrackNumberEnqueued"]), nil
+	case "RemoveTrackRangeFromQueue":
+		start, _ := strconv.Atoi(args["StartingIndex"])
+		n, _ := strconv.Atoi(args["NumberOfTracks"])
+		if start < 1 || n < 0 || start-1+n > len(d.queue) {
+			return nil, Fault(402)
+		}
+		d.queue = slices.Delete(d.queue, start-1, start-1+n)
+		return map[string]string{"NewUpdateID": "1"}, nil
+	case "ReorderTracksInQueue":
+		start, _ := strconv.Atoi(args["StartingIndex"])
+		n, _ := strconv.Atoi(args["NumberOfTracks"])
+		before, _ := strconv.Atoi(args["InsertBefore"])
+		if start < 1 || n < 0 || start-1+n > len(d.queue) || before < 1 || before > len(d.queue)+1 {
+			return nil, Fault(402)
+		}
+		moved := slices.Clone(d.queue[start-1 : start-1+n])
+		rest := slices.Delete(slices.Clone(d.queue), start-1, start-1+n)
+		at := before - 1
+		if at > start-1 {
+			at -= n // the tracks before the insertion point have moved up
+		}
+		d.queue = slices.Insert(rest, min(at, len(rest)), moved...)
+	case "RemoveAllTracksFromQueue":
+		d.queue = nil
+	}
+	return nil, nil
+}
+
+// zoneGroupState answers GetZoneGroupState.
+// It locks each device of the group in turn, so it must be called without d.mu held.
+func (d *Device) zoneGroupState(map[string]string) (map[string]string, error) {
+	d.mu.Lock()
+	group := d.group
+	d.mu.Unlock()
+	if group == nil {
+		group = []*Device{d}
+	}
+	var buf strings.Builder
+	fmt.Fprintf(&buf, `<ZoneGroupState><ZoneGroups><ZoneGroup Coordinator="%s" ID="%s:1">`, group[0].UUID, group[0].UUID)
+	for _, m := range group {
+		m.mu.Lock()
+		zone := m.ZoneName
+		m.mu.Unlock()
+		fmt.Fprintf(&buf, `<ZoneGroupMember UUID="%s" Location="%s" ZoneName="%s" Icon="x-rincon-roomicon:living" SoftwareVersion="79.1-56030" SWGen="2"/>`,
+			m.UUID, m.Location(), xmlEscape(zone))
+	}
+	buf.WriteString(`</ZoneGroup></ZoneGroups></ZoneGroupState>`)
+	return map[string]string{"ZoneGroupState": buf.String()}, nil
+}
+
+// browse answers Browse, for the queue ("Q:0"), the Sonos playlists ("SQ:")
+// and each playlist ("SQ:0" and so on). Other objects are empty. d.mu must be held.
+func (d *Device) browse(args map[string]string) (map[string]string, error) {
+	var objs []string // DIDL-Lite items or containers
+	id := args["ObjectID"]
+	switch {
+	case id == "Q:0":
+		for i, t := range d.queue {
+			objs = append(objs, trackDIDL(fmt.Sprintf("Q:0/%d", i+1), "Q:0", t))
+		}
+	case id == "SQ:":
+		for i, pl := range d.playlists {
+			objs = append(objs, fmt.Sprintf(`<container id="SQ:%d" parentID="SQ:" restricted="true"><dc:title>%s</dc:title><upnp:class>object.container.playlistContainer</upnp:class><res protocolInfo="file:*:audio/mpegurl:*">%s</res></container>`,
+				i, xmlEscape(pl.title), playlistURI(i)))
+		}
+	case strings.HasPrefix(id, "SQ:"):
+		i, err := strconv.Atoi(strings.TrimPrefix(id, "SQ:"))
+		if err != nil || i < 0 || i >= len(d.playlists) {
+			return nil, Fault(701) // no such object
+		}
+		for j, t := range d.playlists[i].tracks {
+			objs = append(objs, trackDIDL(fmt.Sprintf("%s/%d", id, j), id, t))
+		}
+	}
+	total := len(objs)
+	start, _ := strconv.Atoi(args["StartingIndex"])
+	objs = objs[min(max(start, 0), total):]
+	if n, _ := strconv.Atoi(args["RequestedCount"]); n > 0 && n < len(objs) {
+		objs = objs[:n]
+	}
+	return map[string]string{
+		"Result":         `<DIDL-Lite xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/">` + strings.Join(objs, "") + `</DIDL-Lite>`,
+		"NumberReturned": strconv.Itoa(len(objs)),
+		"TotalMatches":   strconv.Itoa(total),
+		"UpdateID":       "1",
+	}, nil
+}
+
+func trackDIDL(id, parentID string, t Track) string {
+	return fmt.Sprintf(`<item id="%s" parentID="%s" restricted="true"><dc:title>%s</dc:title><dc:creator>%s</dc:creator><upnp:class>object.item.audioItem.musicTrack</upnp:class><res protocolInfo="http-get:*:audio/mpeg:*">%s</res></item>`,
+		id, parentID, xmlEscape(t.Title), xmlEscape(t.Creator), xmlEscape(t.URI))
+}
+
+// playlistURI returns the URI for adding the device's ith playlist to a queue.
+func playlistURI(i int) string {
+	return fmt.Sprintf("file:///jffs/settings/savedqueues.rsq#%d", i)
+}
+
+// enqueue inserts tracks into the queue at a 1-based position, or at the end for position 0,
+// and returns the response to an AddURIToQueue or AddMultipleURIsToQueue. d.mu must be held.
+func (d *Device) enqueue(tracks []Track, position string) map[string]string {
+	at := len(d.queue)
+	if p, _ := strconv.Atoi(position); p > 0 && p <= len(d.queue) {
+		at = p - 1
+	}
+	d.queue = slices.Insert(d.queue, at, tracks...)
+	return map[string]string{
+		"FirstTrackNumberEnqueued": strconv.Itoa(at + 1),
+		"NumTracksAdded":           strconv.Itoa(len(tracks)),
+		"NewQueueLength":           strconv.Itoa(len(d.queue)),
+		"NewUpdateID":              "1",
+	}
+}
+
+// parseArgs parses the arguments of the action in a SOAP request body.
+func parseArgs(r io.Reader) (map[string]string, error) {
+	dec := xml.NewDecoder(r)
+	args := make(map[string]string)
+	depth := 0 // 1 = Envelope, 2 = Body, 3 = action, 4 = argument
+	var name string
+	var value strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return args, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("parsing SOAP request: %w", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 4 {
+				name = tok.Name.Local
+				value.Reset()
+			}
+		case xml.CharData:
+			if depth == 4 {
+				value.Write(tok)
+			}
+		case xml.EndElement:
+			if depth == 4 {
+				args[name] = value.String()
+			}
+			depth--
+		}
+	}
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+const descriptionTemplate = `<?xml version="1.0" encoding="utf-8" ?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+  <specVersion><major>1</major><minor>0</minor></specVersion>
+  <device>
+    <deviceType>urn:schemas-upnp-org:device:ZonePlayer:1</deviceType>
+    <friendlyName>127.0.0.1 - Sonos One</friendlyName>
+    <manufacturer>Sonos, Inc.</manufacturer>
+    <manufacturerURL>http://www.sonos.com</manufacturerURL>
+    <modelNumber>S18</modelNumber>
+    <modelDescription>Sonos One</modelDescription>
+    <modelName>Sonos One</modelName>
+    <softwareVersion>79.1-56030</softwareVersion>
+    <swGen>2</swGen>
+    <roomName>%s</roomName>
+    <displayName>One</displayName>
+    <UDN>uuid:%s</UDN>
+    <serviceList>
+      <service><serviceType>urn:schemas-upnp-org:service:AlarmClock:1</serviceType><serviceId>urn:upnp-org:serviceId:AlarmClock</serviceId><controlURL>/AlarmClock/Control</controlURL><eventSubURL>/AlarmClock/Event</eventSubURL><SCPDURL>/xml/AlarmClock1.xml</SCPDURL></service>
+      <service><serviceType>urn:schemas-upnp-org:service:MusicServices:1</serviceType><serviceId>urn:upnp-org:serviceId:MusicServices</serviceId><controlURL>/MusicServices/Control</controlURL><eventSubURL>/MusicServices/Event</eventSubURL><SCPDURL>/xml/MusicServices1.xml</SCPDURL></service>
+      <service><serviceType>urn:schemas-upnp-org:service:AudioIn:1</serviceType><serviceId>urn:upnp-org:serviceId:AudioIn</serviceId><controlURL>/AudioIn/Control</controlURL><eventSubURL>/AudioIn/Event</eventSubURL><SCPDURL>/xml/AudioIn1.xml</SCPDURL></service>
+      <service><serviceType>urn:schemas-upnp-org:service:DeviceProperties:1</serviceType><serviceId>urn:upnp-org:serviceId:DeviceProperties</serviceId><controlURL>/DeviceProperties/Control</controlURL><eventSubURL>/DeviceProperties/Event</eventSubURL><SCPDURL>/xml/DeviceProperties1.xml</SCPDURL></service>
+      <service><serviceType>urn:schemas-upnp-org:service:SystemProperties:1</serviceType><serviceId>urn:upnp-org:serviceId:SystemProperties</serviceId><controlURL>/SystemProperties/Control</controlURL><eventSubURL>/SystemProperties/Event</eventSubURL><SCPDURL>/xml/SystemProperties1.xml</SCPDURL></service>
+      <service><serviceType>urn:schemas-upnp-org:service:ZoneGroupTopology:1</serviceType><serviceId>urn:upnp-org:serviceId:ZoneGroupTopology</serviceId><controlURL>/ZoneGroupTopology/Control</controlURL><eventSubURL>/ZoneGroupTopology/Event</eventSubURL><SCPDURL>/xml/ZoneGroupTopology1.xml</SCPDURL></service>
+    </serviceList>
+    <deviceList>
+      <device>
+        <deviceType>urn:schemas-upnp-org:device:MediaServer:1</deviceType>
+        <friendlyName>127.0.0.1 - Sonos One Media Server</friendlyName>
+        <manufacturer>Sonos, Inc.</manufacturer>
+        <modelName>Sonos One</modelName>
+        <UDN>uuid:%[2]s_MS</UDN>
+        <serviceList>
+          <service><serviceType>urn:schemas-upnp-org:service:ContentDirectory:1</serviceType><serviceId>urn:upnp-org:serviceId:ContentDirectory</serviceId><controlURL>/MediaServer/ContentDirectory/Control</controlURL><eventSubURL>/MediaServer/ContentDirectory/Event</eventSubURL><SCPDURL>/xml/ContentDirectory1.xml</SCPDURL></service>
+        </serviceList>
+      </device>
+      <device>
+        <deviceType>urn:schemas-upnp-org:device:MediaRenderer:1</deviceType>
+        <friendlyName>127.0.0.1 - Sonos One Media Renderer</friendlyName>
+        <manufacturer>Sonos, Inc.</manufacturer>
+        <modelName>Sonos One</modelName>
+        <UDN>uuid:%[2]s_MR</UDN>
+        <serviceList>
+          <service><serviceType>urn:schemas-upnp-org:service:RenderingControl:1</serviceType><serviceId>urn:upnp-org:serviceId:RenderingControl</serviceId><controlURL>/MediaRenderer/RenderingControl/Control</controlURL><eventSubURL>/MediaRenderer/RenderingControl/Event</eventSubURL><SCPDURL>/xml/RenderingControl1.xml</SCPDURL></service>
+          <service><serviceType>urn:schemas-upnp-org:service:AVTransport:1</serviceType><serviceId>urn:upnp-org:serviceId:AVTransport</serviceId><controlURL>/MediaRenderer/AVTransport/Control</controlURL><eventSubURL>/MediaRenderer/AVTransport/Event</eventSubURL><SCPDURL>/xml/AVTransport1.xml</SCPDURL></service>
+          <service><serviceType>urn:schemas-sonos-com:service:Queue:1</serviceType><serviceId>urn:sonos-com:serviceId:Queue</serviceId><controlURL>/MediaRenderer/Queue/Control</controlURL><eventSubURL>/MediaRenderer/Queue/Event</eventSubURL><SCPDURL>/xml/Queue1.xml</SCPDURL></service>
+          <service><serviceType>urn:schemas-upnp-org:service:GroupRenderingControl:1</serviceType><serviceId>urn:upnp-org:serviceId:GroupRenderingControl</serviceId><controlURL>/MediaRenderer/GroupRenderingControl/Control</controlURL><eventSubURL>/MediaRenderer/GroupRenderingControl/Event</eventSubURL><SCPDURL>/xml/GroupRenderingControl1.xml</SCPDURL></service>
+        </serviceList>
+      </device>
+    </deviceList>
+  </device>
+</root>
+`
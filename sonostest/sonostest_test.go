@@ -0,0 +1,184 @@
+package sonostest_test
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/dsymonds/sonos"
+	"github.com/dsymonds/sonos/sonostest"
+)
+
+// newDevice returns a fake in the named zone, and a client's device for it.
+func newDevice(t *testing.T, zone string) (*sonostest.Device, *sonos.Device) {
+	t.Helper()
+	ctx := context.Background()
+	fake := sonostest.NewDevice(zone)
+	t.Cleanup(fake.Close)
+	c, err := sonos.NewClientFromIPs(ctx, nil)
+	if err != nil {
+		t.Fatalf("NewClientFromIPs: %v", err)
+	}
+	if err := c.AddDeviceByURL(ctx, fake.Location()); err != nil {
+		t.Fatalf("AddDeviceByURL: %v", err)
+	}
+	return fake, c.Devices()[0]
+}
+
+func TestState(t *testing.T) {
+	ctx := context.Background()
+	fake, d := newDevice(t, "Kitchen")
+	if err := d.SetVolume(ctx, 31); err != nil {
+		t.Fatalf("SetVolume: %v", err)
+	}
+	if got := fake.State("Volume"); got != "31" {
+		t.Errorf("fake volume is %s, want 31", got)
+	}
+	vol, err := d.Volume(ctx)
+	if err != nil {
+		t.Fatalf("Volume: %v", err)
+	}
+	if vol != 31 {
+		t.Errorf("Volume = %d, want 31", vol)
+	}
+	calls := fake.Calls()
+	if len(calls) < 2 {
+		t.Fatalf("got %d calls, want at least 2", len(calls))
+	}
+	set := calls[len(calls)-2]
+	if set.Action != "SetVolume" || set.Service != sonos.RenderingControlService || set.Args["DesiredVolume"] != "31" {
+		t.Errorf("SetVolume recorded as %+v", set)
+	}
+}
+
+func TestHandle(t *testing.T) {
+	ctx := context.Background()
+	fake, d := newDevice(t, "Kitchen")
+	fake.Handle("Play", func(map[string]string) (map[string]string, error) {
+		return nil, sonostest.Fault(701)
+	})
+	if code := sonos.ErrorCode(d.Play(ctx)); code != 701 {
+		t.Errorf("Play returned error code %d, want 701", code)
+	}
+	if got := fake.State("TransportState"); got != "STOPPED" {
+		t.Errorf("transport state is %q after a refused Play", got)
+	}
+}
+
+// reorder moves tracks in the fake's queue.
+func reorder(t *testing.T, d *sonos.Device, start, n, before int) error {
+	t.Helper()
+	return d.Action(context.Background(), sonos.AVTransportService, "ReorderTracksInQueue", struct {
+		InstanceID     string
+		StartingIndex  string
+		NumberOfTracks string
+		InsertBefore   string
+		UpdateID       string
+	}{"0", strconv.Itoa(start), strconv.Itoa(n), strconv.Itoa(before), "0"}, &struct{}{})
+}
+
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+	fake, d := newDevice(t, "Kitchen")
+	var tracks []sonostest.Track
+	for _, uri := range []string{"a", "b", "c", "d"} {
+		tracks = append(tracks, sonostest.Track{URI: uri, Title: "Track " + uri})
+	}
+	fake.SetQueue(tracks...)
+
+	items, err := d.Queue(ctx)
+	if err != nil {
+		t.Fatalf("Queue: %v", err)
+	}
+	if len(items) != 4 || items[1].URI != "b" || items[1].Title != "Track b" {
+		t.Errorf("Queue = %+v", items)
+	}
+
+	tests := []struct {
+		start, n, before int
+		want             string
+	}{
+		{4, 1, 1, "dabc"}, // up
+		{1, 1, 3, "adbc"}, // down, before the original third track
+		{1, 2, 5, "bcad"}, // to the end
+	}
+	for _, tc := range tests {
+		if err := reorder(t, d, tc.start, tc.n, tc.before); err != nil {
+			t.Fatalf("reordering %d+%d before %d: %v", tc.start, tc.n, tc.before, err)
+		}
+		var got string
+		for _, tr := range fake.Queue() {
+			got += tr.URI
+		}
+		if got != tc.want {
+			t.Errorf("after moving %d+%d before %d, queue is %s, want %s", tc.start, tc.n, tc.before, got, tc.want)
+		}
+	}
+	if code := sonos.ErrorCode(reorder(t, d, 4, 2, 1)); code != 402 {
+		t.Errorf("reordering past the end returned error code %d, want 402", code)
+	}
+
+	if err := d.ClearQueue(ctx); err != nil {
+		t.Fatalf("ClearQueue: %v", err)
+	}
+	if n := len(fake.Queue()); n != 0 {
+		t.Errorf("queue has %d tracks after clearing", n)
+	}
+}
+
+func TestPlaylists(t *testing.T) {
+	ctx := context.Background()
+	fake, d := newDevice(t, "Kitchen")
+	id := fake.AddPlaylist("Mix", sonostest.Track{URI: "a"}, sonostest.Track{URI: "b"})
+	pls, err := d.SonosPlaylists(ctx)
+	if err != nil {
+		t.Fatalf("SonosPlaylists: %v", err)
+	}
+	if len(pls) != 1 || pls[0].ID != id || pls[0].Title != "Mix" {
+		t.Fatalf("SonosPlaylists = %+v", pls)
+	}
+	tracks, total, err := d.SonosPlaylistTracks(ctx, id, 1, 0)
+	if err != nil {
+		t.Fatalf("SonosPlaylistTracks: %v", err)
+	}
+	if total != 2 || len(tracks) != 1 || tracks[0].URI != "b" {
+		t.Errorf("SonosPlaylistTracks = %+v, %d", tracks, total)
+	}
+
+	// Adding the playlist's URI adds its tracks.
+	if _, err := d.AddToQueue(ctx, sonos.ServiceItem{URI: pls[0].URI}, sonos.EnqueueOptions{}); err != nil {
+		t.Fatalf("AddToQueue: %v", err)
+	}
+	var got []string
+	for _, tr := range fake.Queue() {
+		got = append(got, tr.URI)
+	}
+	if want := []string{"a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("queue is %q, want %q", got, want)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	ctx := context.Background()
+	lead, ld := newDevice(t, "Living Room")
+	member, md := newDevice(t, "Kitchen")
+	sonostest.Group(lead, member)
+
+	for _, d := range []*sonos.Device{ld, md} {
+		groups, err := d.ZoneGroups(ctx)
+		if err != nil {
+			t.Fatalf("ZoneGroups: %v", err)
+		}
+		if len(groups) != 1 || groups[0].Coordinator != lead.UUID || len(groups[0].Members) != 2 || groups[0].Members[1].ZoneName != "Kitchen" {
+			t.Errorf("ZoneGroups = %+v", groups)
+		}
+	}
+	// Each device here has its own client, so nothing redirects the member's Play.
+	if code := sonos.ErrorCode(md.Play(ctx)); code != sonos.ErrCodeNotCoordinator {
+		t.Errorf("Play on member returned error code %d, want %d", code, sonos.ErrCodeNotCoordinator)
+	}
+	if err := ld.Play(ctx); err != nil {
+		t.Errorf("Play on coordinator: %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package sonos
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/huin/goupnp"
+)
+
+const (
+	ssdpAddr      = "239.255.255.250:1900"
+	ssdpNumSends  = 3
+	defaultSSDPMX = 2
+)
+
+// ssdpSearch performs an SSDP search for the given target on the configured
+// interfaces, and returns the locations of the device descriptions of the
+// devices that responded.
+func ssdpSearch(ctx context.Context, st string, o options) ([]*url.URL, error) {
+	mx := o.mx
+	if mx < 1 {
+		mx = defaultSSDPMX
+	}
+	deadline := searchDeadline(ctx, o)
+	addrs, err := multicastAddrs(o.iface)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("no usable network interfaces")
+	}
+
+	var (
+		mu   sync.Mutex
+		seen = make(map[string]bool)
+		locs []*url.URL
+		errs []error
+	)
+	found := func(loc *url.URL, usn string) {
+		mu.Lock()
+		defer mu.Unlock()
+		id := loc.String() + "\x00" + usn
+		if !seen[id] {
+			seen[id] = true
+			locs = append(locs, loc)
+		}
+	}
+	var wg sync.WaitGroup
+	for _, addr := range addrs {
+		addr := addr
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := ssdpSearchAddr(ctx, addr, st, mx, deadline, found); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("searching from %s: %w", addr, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Whatever was found before ctx was done is still worth returning.
+	if len(locs) == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+	}
+	return locs, nil
+}
+
+// ssdpSearchAddr performs an SSDP search from a single local address.
+// It calls found for each matching response.
+func ssdpSearchAddr(ctx context.Context, laddr net.IP, st string, mx int, deadline time.Time, found func(loc *url.URL, usn string)) error {
+	conn, err := net.ListenPacket("udp4", net.JoinHostPort(laddr.String(), "0"))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	defer stopOnDone(ctx, conn)()
+
+	dest, err := net.ResolveUDPAddr("udp4", ssdpAddr)
+	if err != nil {
+		return err
+	}
+	req := "M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: " + ssdpAddr + "\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: " + strconv.Itoa(mx) + "\r\n" +
+		"ST: " + st + "\r\n" +
+		"\r\n"
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	for i := 0; i < ssdpNumSends; i++ {
+		if _, err := conn.WriteTo([]byte(req), dest); err != nil {
+			return err
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	buf := make([]byte, 2048)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			var nerr net.Error
+			if errors.As(err, &nerr) && nerr.Timeout() {
+				return nil
+			}
+			return err
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(buf[:n])), nil)
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("ST") != st {
+			continue
+		}
+		loc, err := resp.Location()
+		if err != nil {
+			continue
+		}
+		found(loc, resp.Header.Get("USN"))
+	}
+}
+
+// searchDeadline returns when a multicast search should stop waiting for responses.
+// It ends the search a little before ctx's deadline,
+// leaving time to fetch the descriptions of the devices that were found.
+func searchDeadline(ctx context.Context, o options) time.Time {
+	mx := o.mx
+	if mx < 1 {
+		mx = defaultSSDPMX
+	}
+	deadline := time.Now().Add(time.Duration(mx)*time.Second + 100*time.Millisecond)
+	if o.searchTimeout > 0 && time.Now().Add(o.searchTimeout).Before(deadline) {
+		deadline = time.Now().Add(o.searchTimeout)
+	}
+	if d, ok := ctx.Deadline(); ok {
+		headroom := min(time.Until(d)/4, time.Second)
+		if end := d.Add(-headroom); end.Before(deadline) {
+			deadline = end
+		}
+	}
+	return deadline
+}
+
+// stopOnDone interrupts any blocked reads on conn once ctx is done.
+// The returned function must be called once conn is no longer in use.
+func stopOnDone(ctx context.Context, conn net.PacketConn) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
+
+// multicastAddrs returns the IPv4 addresses of the up, multicast-capable,
+// non-loopback network interfaces. If iface is non-empty, only that interface is considered.
+func multicastAddrs(iface string) ([]net.IP, error) {
+	var ifaces []net.Interface
+	if iface != "" {
+		ifi, err := net.InterfaceByName(iface)
+		if err != nil {
+			return nil, err
+		}
+		ifaces = []net.Interface{*ifi}
+	} else {
+		var err error
+		ifaces, err = net.Interfaces()
+		if err != nil {
+			return nil, fmt.Errorf("listing network interfaces: %w", err)
+		}
+	}
+
+	var ips []net.IP
+	for _, ifi := range ifaces {
+		if ifi.Flags&net.FlagMulticast == 0 || ifi.Flags&net.FlagLoopback != 0 || ifi.Flags&net.FlagUp == 0 {
+			continue
+		}
+		addrs, err := ifi.Addrs()
+		if err != nil {
+			return nil, fmt.Errorf("listing addresses of %s: %w", ifi.Name, err)
+		}
+		for _, addr := range addrs {
+			ipn, ok := addr.(*net.IPNet)
+			if !ok || ipn.IP.To4() == nil {
+				continue
+			}
+			ips = append(ips, ipn.IP)
+		}
+	}
+	return ips, nil
+}
+
+// description is the Sonos-specific part of a device description.
+type description struct {
+	RoomName    string `xml:"device>roomName"`
+	DisplayName string `xml:"device>displayName"` // e.g. "Play:1"
+	SWGen       string `xml:"device>swGen"`       // "1" or "2"
+}
+
+// fetchRootDevice fetches and parses a UPnP device description.
+func fetchRootDevice(ctx context.Context, hc *http.Client, loc *url.URL) (*goupnp.RootDevice, description, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", loc.String(), nil)
+	if err != nil {
+		return nil, description{}, err
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, description{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, description{}, fmt.Errorf("fetching %s: %s", loc, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, description{}, fmt.Errorf("reading device description from %s: %w", loc, err)
+	}
+
+	root := new(goupnp.RootDevice)
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	dec.DefaultSpace = goupnp.DeviceXMLNamespace
+	if err := dec.Decode(root); err != nil {
+		return nil, description{}, fmt.Errorf("decoding device description from %s: %w", loc, err)
+	}
+	var desc description
+	dec = xml.NewDecoder(bytes.NewReader(body))
+	dec.DefaultSpace = goupnp.DeviceXMLNamespace
+	if err := dec.Decode(&desc); err != nil {
+		return nil, description{}, fmt.Errorf("decoding device description from %s: %w", loc, err)
+	}
+	base := loc
+	if root.URLBaseStr != "" {
+		base, err = url.Parse(root.URLBaseStr)
+		if err != nil {
+			return nil, description{}, fmt.Errorf("parsing URLBase %q: %w", root.URLBaseStr, err)
+		}
+	}
+	root.SetURLBase(base)
+	return root, desc, nil
+}
@@ -0,0 +1,23 @@
+package sonos
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSearchDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ctxDeadline, _ := ctx.Deadline()
+	deadline := searchDeadline(ctx, options{})
+	if headroom := ctxDeadline.Sub(deadline); headroom < 200*time.Millisecond {
+		t.Errorf("search ends %v before the context deadline, want at least 200ms to fetch descriptions", headroom)
+	}
+
+	// Without a context deadline, the search waits for MX seconds.
+	deadline = searchDeadline(context.Background(), options{mx: 1})
+	if wait := time.Until(deadline); wait < time.Second || wait > 2*time.Second {
+		t.Errorf("search waits %v with MX 1, want about a second", wait)
+	}
+}
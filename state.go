@@ -0,0 +1,176 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/huin/goupnp/dcps/av1"
+)
+
+// SystemState is a snapshot of a household's settings, for backing up with ExportState
+// and restoring with ImportState. It encodes naturally as JSON.
+type SystemState struct {
+	Time   time.Time
+	Zones  []ZoneSnapshot
+	Groups [][]string // zone names of each group of more than one zone, coordinator first
+	Alarms []Alarm
+
+	// Playlists are references to the Sonos playlists; their contents are not saved.
+	Playlists []SonosPlaylist
+}
+
+// ZoneSnapshot is the state of a single zone within a SystemState.
+type ZoneSnapshot struct {
+	Name   string
+	Icon   string // e.g. "x-rincon-roomicon:living"
+	Volume int
+	Mute   bool
+	EQ     EQPreset
+	Media  MediaInfo // what the zone was playing from; not restored
+}
+
+// ExportState captures the household's zones, groups, volumes, EQ, alarms and Sonos playlists.
+func (c *Client) ExportState(ctx context.Context) (*SystemState, error) {
+	zones, err := c.Zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	st := &SystemState{
+		Time:  time.Now(),
+		Zones: make([]ZoneSnapshot, len(zones)),
+	}
+	names := make([]string, len(zones))
+	index := make(map[string]int)
+	for i, z := range zones {
+		names[i] = z.Name
+		index[z.Name] = i
+		st.Zones[i] = ZoneSnapshot{Name: z.Name, Icon: z.Icon}
+	}
+	err = c.Apply(ctx, names, func(d *Device) error {
+		zs := &st.Zones[index[d.RoomName()]]
+		var err error
+		if zs.Volume, err = d.Volume(ctx); err != nil {
+			return err
+		}
+		if zs.Mute, err = d.Mute(ctx); err != nil {
+			return err
+		}
+		if zs.EQ, err = d.CurrentEQ(ctx); err != nil {
+			return err
+		}
+		if zs.Media, err = d.MediaInfo(ctx); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := c.zoneGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		var lead string
+		var rest []string
+		for _, m := range g.Members {
+			if m.Invisible {
+				continue
+			}
+			if m.UUID == g.Coordinator {
+				lead = m.ZoneName
+			} else {
+				rest = append(rest, m.ZoneName)
+			}
+		}
+		if lead != "" && len(rest) > 0 {
+			st.Groups = append(st.Groups, append([]string{lead}, rest...))
+		}
+	}
+
+	if st.Alarms, err = c.Alarms(ctx); err != nil {
+		return nil, err
+	}
+	for _, d := range c.Devices() {
+		if len(d.dev.FindService(av1.URN_ContentDirectory_1)) == 0 {
+			continue
+		}
+		if st.Playlists, err = d.SonosPlaylists(ctx); err != nil {
+			return nil, err
+		}
+		break
+	}
+	return st, nil
+}
+
+// ImportState reapplies what it can of a SystemState from ExportState:
+// zone icons, volumes and EQ, groups, and any alarms that no longer exist.
+// Playback and playlists are not restored.
+// It carries on after failures, and returns them combined.
+func (c *Client) ImportState(ctx context.Context, st *SystemState) error {
+	var errs []error
+
+	snaps := make(map[string]ZoneSnapshot)
+	var names []string
+	for _, zs := range st.Zones {
+		snaps[zs.Name] = zs
+		names = append(names, zs.Name)
+	}
+	errs = append(errs, c.Apply(ctx, names, func(d *Device) error {
+		zs := snaps[d.RoomName()]
+		if err := d.SetZoneAttributes(ctx, ZoneAttributes{Icon: zs.Icon}); err != nil {
+			return err
+		}
+		if err := d.SetVolume(ctx, zs.Volume); err != nil {
+			return err
+		}
+		if err := d.SetMute(ctx, zs.Mute); err != nil {
+			return err
+		}
+		return d.ApplyEQ(ctx, zs.EQ)
+	}))
+
+	for _, g := range st.Groups {
+		lead, err := c.ZoneDevice(ctx, g[0])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, c.Apply(ctx, g[1:], func(d *Device) error { return d.Join(ctx, lead) }))
+	}
+
+	if len(st.Alarms) > 0 {
+		errs = append(errs, c.restoreAlarms(ctx, st.Alarms))
+	}
+	return errors.Join(errs...)
+}
+
+// restoreAlarms creates any of the alarms that do not exist, ignoring their IDs.
+func (c *Client) restoreAlarms(ctx context.Context, alarms []Alarm) error {
+	existing, err := c.Alarms(ctx)
+	if err != nil {
+		return err
+	}
+	have := make(map[Alarm]bool)
+	for _, a := range existing {
+		a.ID = 0
+		have[a] = true
+	}
+	for _, a := range alarms {
+		a.ID = 0
+		// Compare the alarm as it would be created.
+		if v, err := c.alarmVolume(a); err == nil {
+			a.Volume = v
+		}
+		if have[a] {
+			continue
+		}
+		if _, err := c.CreateAlarm(ctx, a); err != nil {
+			return fmt.Errorf("restoring alarm for %s: %w", alarmTime(a.StartTime), err)
+		}
+	}
+	return nil
+}
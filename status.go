@@ -0,0 +1,194 @@
+// This file contains access to the non-UPnP HTTP endpoints that players serve.
+
+package sonos
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+// errNotFound is returned by fetchStatus if the device does not serve the path.
+var errNotFound = errors.New("not found")
+
+// baseURL returns the root URL of the device's HTTP server (e.g. "http://192.168.1.10:1400").
+func (d *Device) baseURL() (*url.URL, error) {
+	svcs := d.upnp().FindService(DevicePropertiesService)
+	if len(svcs) == 0 {
+		return nil, fmt.Errorf("unknown service %q for device", DevicePropertiesService)
+	}
+	u := svcs[0].ControlURL.URL
+	return &url.URL{Scheme: u.Scheme, Host: u.Host}, nil
+}
+
+// httpGet fetches the given path and query from the device and returns the response body.
+func (d *Device) httpGet(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	base, err := d.baseURL()
+	if err != nil {
+		return nil, err
+	}
+	u := base.JoinPath(path)
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", u, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", u, err)
+	}
+	return body, nil
+}
+
+// fetchStatus fetches the given path from the device and decodes the XML response into v.
+func (d *Device) fetchStatus(ctx context.Context, path string, v interface{}) error {
+	body, err := d.httpGet(ctx, path, nil)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return nil
+}
+
+// statusFile fetches a status page that wraps the contents of a single file or command output.
+func (d *Device) statusFile(ctx context.Context, path string) (string, error) {
+	var info struct {
+		File    string `xml:"File"`
+		Command string `xml:"Command"`
+	}
+	if err := d.fetchStatus(ctx, path, &info); err != nil {
+		return "", err
+	}
+	return info.File + info.Command, nil
+}
+
+// ErrNoBattery is returned by BatteryStatus for devices without a battery.
+var ErrNoBattery = errors.New("device has no battery")
+
+// BatteryStatus is the state of a portable device's battery.
+type BatteryStatus struct {
+	Level       int    // percent charged, in range [0,100]
+	Charging    bool   // whether the device is on external power
+	PowerSource string // e.g. "BATTERY", "SONOS_CHARGING_RING", "USB_POWER"
+	Health      string // e.g. "GREEN"
+	Temperature string // e.g. "NORMAL"
+}
+
+// BatteryStatus returns the battery state of a portable device (e.g. a Move or Roam).
+// It returns ErrNoBattery for other devices.
+func (d *Device) BatteryStatus(ctx context.Context) (BatteryStatus, error) {
+	var info struct {
+		Data []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"LocalBatteryStatus>Data"`
+	}
+	err := d.fetchStatus(ctx, "/status/batterystatus", &info)
+	if err == errNotFound {
+		return BatteryStatus{}, ErrNoBattery
+	} else if err != nil {
+		return BatteryStatus{}, fmt.Errorf("getting battery status: %w", err)
+	}
+	if len(info.Data) == 0 {
+		return BatteryStatus{}, ErrNoBattery
+	}
+
+	var bs BatteryStatus
+	for _, data := range info.Data {
+		switch data.Name {
+		case "Level":
+			bs.Level, err = strconv.Atoi(data.Value)
+			if err != nil {
+				return BatteryStatus{}, fmt.Errorf("parsing battery level %q: %w", data.Value, err)
+			}
+		case "PowerSource":
+			bs.PowerSource = data.Value
+			bs.Charging = data.Value != "BATTERY"
+		case "Health":
+			bs.Health = data.Value
+		case "Temperature":
+			bs.Temperature = data.Value
+		}
+	}
+	return bs, nil
+}
+
+// NetworkStatus describes how a device is connected to the network.
+type NetworkStatus struct {
+	Wired          bool // whether the device has an Ethernet link
+	WiFiEnabled    bool // whether the device's WiFi radio is on
+	ChannelFreq    int  // WiFi channel frequency in MHz, or 0 if unknown
+	SignalStrength int  // strongest wireless signal seen (RSSI), or 0 if unknown
+}
+
+var rssiRE = regexp.MustCompile(`(?i)rssi[^0-9-]*(-?\d+)`)
+
+// NetworkStatus reports how the device is connected to the network.
+// The signal strength is gathered on a best effort basis from the device's status pages.
+func (d *Device) NetworkStatus(ctx context.Context) (NetworkStatus, error) {
+	m, _, err := d.topologyMember(ctx)
+	if err != nil {
+		return NetworkStatus{}, err
+	}
+	ns := NetworkStatus{
+		Wired:       m.EthLink,
+		WiFiEnabled: m.WiFiEnabled,
+		ChannelFreq: m.ChannelFreq,
+	}
+	if ns.WiFiEnabled {
+		status, err := d.statusFile(ctx, "/status/proc/ath_rincon/status")
+		if err != nil && err != errNotFound {
+			return NetworkStatus{}, fmt.Errorf("getting wireless status: %w", err)
+		}
+		for _, match := range rssiRE.FindAllStringSubmatch(status, -1) {
+			rssi, err := strconv.Atoi(match[1])
+			if err == nil && (ns.SignalStrength == 0 || rssi > ns.SignalStrength) {
+				ns.SignalStrength = rssi
+			}
+		}
+	}
+	return ns, nil
+}
+
+// SetWiFi turns the device's WiFi radio on or off.
+// Turning it off persists across reboots, so it is refused unless the device is wired.
+//
+// DeviceProperties has no action for the radio; the device only exposes it
+// through its /wifictrl page, which is what the Sonos apps use too.
+func (d *Device) SetWiFi(ctx context.Context, on bool) error {
+	state := "persist-off"
+	if on {
+		state = "on"
+	} else {
+		m, _, err := d.topologyMember(ctx)
+		if err != nil {
+			return err
+		}
+		if !m.EthLink {
+			return fmt.Errorf("%s is not wired, so turning off WiFi would disconnect it", d.RoomName())
+		}
+	}
+	if _, err := d.httpGet(ctx, "/wifictrl", url.Values{"wifi": {state}}); err != nil {
+		return fmt.Errorf("setting WiFi state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package sonos
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSetWiFiUnwired(t *testing.T) {
+	fake := newFake(t, "Kitchen")
+	c := newTestClient(t, nil, fake)
+	err := device(t, c, fake).SetWiFi(context.Background(), false)
+	if err == nil || !strings.Contains(err.Error(), "not wired") {
+		t.Errorf("SetWiFi(false) on a wireless device returned %v, want it refused", err)
+	}
+}
@@ -0,0 +1,49 @@
+package sonos
+
+import (
+	"context"
+	"fmt"
+)
+
+// SystemProperty returns a string stored on the device under the given name.
+// Devices share such strings across the household.
+// A missing property is reported as a *UPnPError.
+func (d *Device) SystemProperty(ctx context.Context, name string) (string, error) {
+	var resp struct {
+		StringValue string
+	}
+	err := d.soap(ctx, SystemPropertiesService, "GetString", struct {
+		VariableName string
+	}{VariableName: name}, &resp)
+	if err != nil {
+		return "", fmt.Errorf("getting system property %q: %w", name, err)
+	}
+	return resp.StringValue, nil
+}
+
+// SetSystemProperty stores a string on the device under the given name,
+// replacing any existing value. Values should be small.
+func (d *Device) SetSystemProperty(ctx context.Context, name, value string) error {
+	err := d.soap(ctx, SystemPropertiesService, "SetString", struct {
+		VariableName string
+		StringValue  string
+	}{
+		VariableName: name,
+		StringValue:  value,
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("setting system property %q: %w", name, err)
+	}
+	return nil
+}
+
+// RemoveSystemProperty removes the string stored on the device under the given name.
+func (d *Device) RemoveSystemProperty(ctx context.Context, name string) error {
+	err := d.soap(ctx, SystemPropertiesService, "Remove", struct {
+		VariableName string
+	}{VariableName: name}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("removing system property %q: %w", name, err)
+	}
+	return nil
+}
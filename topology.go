@@ -0,0 +1,214 @@
+package sonos
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ZoneGroup is a group of zones playing in sync.
+type ZoneGroup struct {
+	ID          string
+	Coordinator string // UUID of the coordinating member
+	Members     []ZoneGroupMember
+}
+
+// ZoneGroupMember is a single device in a ZoneGroup.
+type ZoneGroupMember struct {
+	UUID            string
+	Location        string // URL of the device description
+	ZoneName        string
+	Icon            string
+	SoftwareVersion string
+	ChannelMapSet   string // set for stereo pairs
+	HTSatChanMapSet string // set for home theater setups
+	Invisible       bool   // true for bonded devices not shown as their own zone
+	EthLink         bool   // whether the device has an Ethernet link
+	WiFiEnabled     bool
+	ChannelFreq     int // WiFi channel frequency in MHz, or 0 if unknown
+	BootSeq         int // increases each time the device starts
+	Satellites      []ZoneGroupMember
+}
+
+type xmlZoneGroup struct {
+	ID          string          `xml:"ID,attr"`
+	Coordinator string          `xml:"Coordinator,attr"`
+	Members     []xmlZoneMember `xml:"ZoneGroupMember"`
+}
+
+type xmlZoneMember struct {
+	UUID            string          `xml:"UUID,attr"`
+	Location        string          `xml:"Location,attr"`
+	ZoneName        string          `xml:"ZoneName,attr"`
+	Icon            string          `xml:"Icon,attr"`
+	SoftwareVersion string          `xml:"SoftwareVersion,attr"`
+	ChannelMapSet   string          `xml:"ChannelMapSet,attr"`
+	HTSatChanMapSet string          `xml:"HTSatChanMapSet,attr"`
+	Invisible       string          `xml:"Invisible,attr"`
+	EthLink         string          `xml:"EthLink,attr"`
+	WifiEnabled     string          `xml:"WifiEnabled,attr"`
+	ChannelFreq     string          `xml:"ChannelFreq,attr"`
+	BootSeq         string          `xml:"BootSeq,attr"`
+	Satellites      []xmlZoneMember `xml:"Satellite"`
+}
+
+func (xm xmlZoneMember) member() ZoneGroupMember {
+	m := ZoneGroupMember{
+		UUID:            xm.UUID,
+		Location:        xm.Location,
+		ZoneName:        xm.ZoneName,
+		Icon:            xm.Icon,
+		SoftwareVersion: xm.SoftwareVersion,
+		ChannelMapSet:   xm.ChannelMapSet,
+		HTSatChanMapSet: xm.HTSatChanMapSet,
+		Invisible:       xm.Invisible == "1",
+		EthLink:         xm.EthLink == "1",
+		WiFiEnabled:     xm.WifiEnabled == "1",
+	}
+	m.ChannelFreq, _ = strconv.Atoi(xm.ChannelFreq) // may be absent
+	m.BootSeq, _ = strconv.Atoi(xm.BootSeq)
+	for _, xs := range xm.Satellites {
+		m.Satellites = append(m.Satellites, xs.member())
+	}
+	return m
+}
+
+// ZoneGroups returns the zone groups of the household, as seen by the device.
+func (d *Device) ZoneGroups(ctx context.Context) ([]ZoneGroup, error) {
+	var resp struct {
+		ZoneGroupState string // XML
+	}
+	err := d.soap(ctx, ZoneGroupTopologyService, "GetZoneGroupState", struct{}{}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("getting zone group state: %w", err)
+	}
+
+	// Newer firmware wraps <ZoneGroups> in a <ZoneGroupState> element.
+	var state struct {
+		Groups       []xmlZoneGroup `xml:"ZoneGroups>ZoneGroup"`
+		LegacyGroups []xmlZoneGroup `xml:"ZoneGroup"`
+	}
+	if err := xml.Unmarshal([]byte(resp.ZoneGroupState), &state); err != nil {
+		return nil, fmt.Errorf("unmarshaling zone group state: %w", err)
+	}
+	var groups []ZoneGroup
+	for _, xg := range append(state.Groups, state.LegacyGroups...) {
+		g := ZoneGroup{
+			ID:          xg.ID,
+			Coordinator: xg.Coordinator,
+		}
+		for _, xm := range xg.Members {
+			g.Members = append(g.Members, xm.member())
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+// topologyMember returns the device's entry in the zone group topology,
+// along with the group that contains it.
+func (d *Device) topologyMember(ctx context.Context) (ZoneGroupMember, ZoneGroup, error) {
+	groups, err := d.ZoneGroups(ctx)
+	if err != nil {
+		return ZoneGroupMember{}, ZoneGroup{}, err
+	}
+	uid := d.uid()
+	var find func([]ZoneGroupMember) (ZoneGroupMember, bool)
+	find = func(ms []ZoneGroupMember) (ZoneGroupMember, bool) {
+		for _, m := range ms {
+			if m.UUID == uid {
+				return m, true
+			}
+			if sm, ok := find(m.Satellites); ok {
+				return sm, true
+			}
+		}
+		return ZoneGroupMember{}, false
+	}
+	for _, g := range groups {
+		if m, ok := find(g.Members); ok {
+			return m, g, nil
+		}
+	}
+	return ZoneGroupMember{}, ZoneGroup{}, fmt.Errorf("device %s not found in zone group topology", uid)
+}
+
+// A ChannelMap maps a device UUID to the audio channels it plays (e.g. "LF", "RF", "SW").
+type ChannelMap map[string][]string
+
+// parseChannelMap parses a ChannelMapSet or HTSatChanMapSet attribute,
+// which looks like "RINCON_A:LF,RF;RINCON_B:SW".
+func parseChannelMap(s string) ChannelMap {
+	if s == "" {
+		return nil
+	}
+	cm := make(ChannelMap)
+	for _, entry := range strings.Split(s, ";") {
+		uid, chans, ok := strings.Cut(entry, ":")
+		if !ok {
+			continue
+		}
+		cm[uid] = strings.Split(chans, ",")
+	}
+	return cm
+}
+
+// SoftwareVersion returns the device's current firmware version (e.g. "79.1-56030").
+func (d *Device) SoftwareVersion(ctx context.Context) (string, error) {
+	info, err := d.Info(ctx)
+	if err != nil {
+		return "", err
+	}
+	return info.SoftwareVersion, nil
+}
+
+// An Update describes a firmware update that is available for a household.
+type Update struct {
+	Version string
+	URL     string
+}
+
+// CheckForUpdate asks the device whether a firmware update is available.
+// It returns nil if the device is up to date.
+func (d *Device) CheckForUpdate(ctx context.Context) (*Update, error) {
+	var resp struct {
+		UpdateItem string // XML
+	}
+	err := d.soap(ctx, ZoneGroupTopologyService, "CheckForUpdate", struct {
+		UpdateType string
+		CachedOnly string
+		Version    string
+	}{
+		UpdateType: "All",
+		CachedOnly: "0",
+	}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("checking for update: %w", err)
+	}
+	if resp.UpdateItem == "" {
+		return nil, nil
+	}
+	var item struct {
+		Version   string `xml:"Version,attr"`
+		UpdateURL string `xml:"UpdateURL,attr"`
+	}
+	if err := xml.Unmarshal([]byte(resp.UpdateItem), &item); err != nil {
+		return nil, fmt.Errorf("unmarshaling update item: %w", err)
+	}
+	if item.Version == "" {
+		return nil, nil
+	}
+	cur, err := d.SoftwareVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if item.Version == cur {
+		return nil, nil
+	}
+	return &Update{
+		Version: item.Version,
+		URL:     item.UpdateURL,
+	}, nil
+}
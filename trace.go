@@ -0,0 +1,20 @@
+package sonos
+
+import "context"
+
+// A Tracer observes the SOAP actions that a Client performs,
+// such as to record OpenTelemetry spans or latency metrics.
+type Tracer interface {
+	// StartAction is called before each SOAP action; any retries are part of the action.
+	// It returns the context to perform the action with, which may carry a span,
+	// and a function to call with the action's error, or nil, once it is done.
+	StartAction(ctx context.Context, info ActionInfo) (context.Context, func(error))
+}
+
+// ActionInfo describes a SOAP action for a Tracer.
+type ActionInfo struct {
+	Device  string // UUID of the device
+	Zone    string // name of the device's zone, if known
+	Service string // e.g. "urn:schemas-upnp-org:service:AVTransport:1"
+	Action  string // e.g. "Play"
+}
@@ -0,0 +1,87 @@
+package sonos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// TransferPlayback moves what one zone is playing to another:
+// the destination takes on the source's queue or stream, its position,
+// and whether it is playing, and then the source is stopped.
+// Both devices should be zone coordinators.
+func (c *Client) TransferPlayback(ctx context.Context, from, to *Device) error {
+	state, err := from.TransportState(ctx)
+	if err != nil {
+		return err
+	}
+	media, err := from.MediaInfo(ctx)
+	if err != nil {
+		return err
+	}
+	if media.Source() == SourceNone {
+		return fmt.Errorf("%s is not playing anything", from.RoomName())
+	}
+	pos, err := from.PositionInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	if media.Source() == SourceQueue {
+		if err := to.ClearQueue(ctx); err != nil {
+			return err
+		}
+		if _, err := c.CopyQueue(ctx, from, to, EnqueueOptions{}); err != nil {
+			return err
+		}
+		if err := to.selectQueue(ctx); err != nil {
+			return err
+		}
+		if pos.Track > 0 {
+			if err := to.seek(ctx, "TRACK_NR", strconv.Itoa(pos.Track)); err != nil {
+				return err
+			}
+		}
+	} else {
+		if err := to.setAVTransportURI(ctx, media.URI, media.Metadata); err != nil {
+			return fmt.Errorf("setting URI: %w", err)
+		}
+	}
+	// Streams have no duration, and cannot be seeked.
+	if pos.Duration > 0 && pos.Position > 0 {
+		if err := to.seek(ctx, "REL_TIME", formatHMS(pos.Position)); err != nil {
+			return err
+		}
+	}
+
+	if state == Playing || state == Transitioning {
+		if err := to.Play(ctx); err != nil {
+			return err
+		}
+	}
+	if err := from.Stop(ctx); err != nil {
+		return fmt.Errorf("stopping source: %w", err)
+	}
+	return nil
+}
+
+// CopyQueue adds the tracks in one zone's queue to another's, where opts says,
+// without grouping them or changing what either is playing.
+// To replace the destination's queue instead, call ClearQueue on it first.
+// It returns the result of adding the tracks to the destination.
+// Both devices should be zone coordinators.
+func (c *Client) CopyQueue(ctx context.Context, from, to *Device, opts EnqueueOptions) (EnqueueResult, error) {
+	items, err := from.Queue(ctx)
+	if err != nil {
+		return EnqueueResult{}, err
+	}
+	sis := make([]ServiceItem, len(items))
+	for i, it := range items {
+		sis[i] = ServiceItem{URI: it.URI, Metadata: it.Metadata}
+	}
+	res, err := to.AddItemsToQueue(ctx, sis, opts)
+	if err != nil {
+		return res, fmt.Errorf("copying queue: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,203 @@
+package sonos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/huin/goupnp/dcps/av1"
+)
+
+// TransportState is the playback state of a device.
+type TransportState string
+
+const (
+	Playing        TransportState = "PLAYING"
+	PausedPlayback TransportState = "PAUSED_PLAYBACK"
+	Stopped        TransportState = "STOPPED"
+	Transitioning  TransportState = "TRANSITIONING"
+)
+
+// TransportState returns the playback state of the device.
+func (d *Device) TransportState(ctx context.Context) (TransportState, error) {
+	var resp struct {
+		CurrentTransportState  string
+		CurrentTransportStatus string
+		CurrentSpeed           string
+	}
+	err := d.soap(ctx, av1.URN_AVTransport_1, "GetTransportInfo", struct {
+		InstanceID string
+	}{InstanceID: "0"}, &resp)
+	if err != nil {
+		return "", fmt.Errorf("getting transport info: %w", err)
+	}
+	return TransportState(resp.CurrentTransportState), nil
+}
+
+// PositionInfo describes what a device is currently playing.
+type PositionInfo struct {
+	Track    int // 1-based index in the queue, or 0 if not playing from the queue
+	URI      string
+	Duration time.Duration // zero for streams
+	Position time.Duration
+
+	// Track metadata, if available.
+	Title   string
+	Creator string // usually the artist
+	Album   string
+
+	AlbumArtURI string // often relative; see Device.AlbumArtURL
+}
+
+// PositionInfo returns what the device is currently playing, and how far through it is.
+func (d *Device) PositionInfo(ctx context.Context) (PositionInfo, error) {
+	var resp struct {
+		Track         string // ui4
+		TrackDuration string // "H:MM:SS"
+		TrackMetaData string // DIDL-Lite XML
+		TrackURI      string
+		RelTime       string // "H:MM:SS"
+		AbsTime       string
+		RelCount      string
+		AbsCount      string
+	}
+	err := d.soap(ctx, av1.URN_AVTransport_1, "GetPositionInfo", struct {
+		InstanceID string
+	}{InstanceID: "0"}, &resp)
+	if err != nil {
+		return PositionInfo{}, fmt.Errorf("getting position info: %w", err)
+	}
+	pi := PositionInfo{
+		URI:      resp.TrackURI,
+		Duration: parseHMS(resp.TrackDuration),
+		Position: parseHMS(resp.RelTime),
+	}
+	pi.Track, _ = strconv.Atoi(resp.Track)
+
+	didl, err := parseDIDL(resp.TrackMetaData)
+	if err != nil {
+		return PositionInfo{}, fmt.Errorf("parsing track metadata: %w", err)
+	}
+	if len(didl.Items) > 0 {
+		it := didl.Items[0]
+		pi.Title = it.Title
+		pi.Creator = it.Creator
+		pi.Album = it.Album
+		pi.AlbumArtURI = it.AlbumArt
+	}
+	return pi, nil
+}
+
+// MediaInfo describes what a device is playing from.
+type MediaInfo struct {
+	NumTracks int    // number of tracks in the queue, or 1 for a stream
+	URI       string // e.g. "x-rincon-queue:RINCON_000E58000001#0"
+	Metadata  string // DIDL-Lite XML, often empty
+}
+
+// MediaSource is a kind of thing that a device plays from.
+type MediaSource string
+
+const (
+	SourceNone   MediaSource = "none"
+	SourceQueue  MediaSource = "queue"
+	SourceStream MediaSource = "stream" // e.g. internet radio
+	SourceLineIn MediaSource = "line-in"
+	SourceTV     MediaSource = "tv"
+	SourceGroup  MediaSource = "group" // following a group coordinator
+	SourceOther  MediaSource = "other"
+)
+
+// Source reports what kind of thing the media is, judging by its URI.
+func (mi MediaInfo) Source() MediaSource {
+	scheme, _, _ := strings.Cut(mi.URI, ":")
+	switch scheme {
+	case "":
+		return SourceNone
+	case "x-rincon-queue":
+		return SourceQueue
+	case "x-rincon-stream":
+		return SourceLineIn
+	case "x-sonos-htastream":
+		return SourceTV
+	case "x-rincon":
+		return SourceGroup
+	case "x-rincon-mp3radio", "x-sonosapi-stream", "x-sonosapi-radio", "x-sonosapi-hls", "aac", "hls-radio":
+		return SourceStream
+	}
+	return SourceOther
+}
+
+// MediaInfo returns what the device is playing from.
+func (d *Device) MediaInfo(ctx context.Context) (MediaInfo, error) {
+	var resp struct {
+		NrTracks           string // ui4
+		MediaDuration      string
+		CurrentURI         string
+		CurrentURIMetaData string // DIDL-Lite XML
+		NextURI            string
+		NextURIMetaData    string
+		PlayMedium         string
+		RecordMedium       string
+		WriteStatus        string
+	}
+	err := d.soap(ctx, av1.URN_AVTransport_1, "GetMediaInfo", struct {
+		InstanceID string
+	}{InstanceID: "0"}, &resp)
+	if err != nil {
+		return MediaInfo{}, fmt.Errorf("getting media info: %w", err)
+	}
+	mi := MediaInfo{
+		URI:      resp.CurrentURI,
+		Metadata: resp.CurrentURIMetaData,
+	}
+	if mi.Metadata == "NOT_IMPLEMENTED" {
+		mi.Metadata = ""
+	}
+	mi.NumTracks, _ = strconv.Atoi(resp.NrTracks)
+	return mi, nil
+}
+
+// seek moves playback, either to a 1-based queue track ("TRACK_NR")
+// or to a position in the current track ("REL_TIME").
+func (d *Device) seek(ctx context.Context, unit, target string) error {
+	err := d.soap(ctx, av1.URN_AVTransport_1, "Seek", struct {
+		InstanceID string
+		Unit       string
+		Target     string
+	}{
+		InstanceID: "0",
+		Unit:       unit,
+		Target:     target,
+	}, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("seeking to %s %s: %w", unit, target, err)
+	}
+	return nil
+}
+
+// formatHMS formats a duration in the form "H:MM:SS".
+func formatHMS(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+// parseHMS parses a duration of the form "H:MM:SS".
+// It returns zero for anything else, such as "NOT_IMPLEMENTED".
+func parseHMS(s string) time.Duration {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return 0
+		}
+		d += time.Duration(n) * unit
+	}
+	return d
+}
@@ -0,0 +1,167 @@
+package sonos
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/huin/goupnp/soap"
+)
+
+// DeviceEventType is the kind of a DeviceEvent.
+type DeviceEventType int
+
+const (
+	DeviceAdded DeviceEventType = iota
+	DeviceRemoved
+)
+
+func (t DeviceEventType) String() string {
+	switch t {
+	case DeviceAdded:
+		return "added"
+	case DeviceRemoved:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// DeviceEvent reports a device joining or leaving the network.
+// It is sent by Client.Watch.
+type DeviceEvent struct {
+	Type DeviceEventType
+	UDN  string
+	Name string // friendly name from the device description
+	Zone string // may be empty
+}
+
+// Watch polls for changes to the Client's household, and reports them
+// on the returned channel until ctx is done.
+// It sends a *DeviceEvent when a device joins or leaves the network,
+// keeping the Client's devices and zones up to date, a *TopologyEvent
+// when zones are grouped or ungrouped, and a *LibraryEvent when the music library,
+// Sonos playlists or favorites change.
+// See Device.Watch for changes to what a device is playing.
+// How often it polls is set by WithPollInterval and WithRediscoverInterval.
+// The channel is closed once ctx is done, and must be drained.
+func (c *Client) Watch(ctx context.Context) <-chan Event {
+	ch := make(chan Event)
+	go func() {
+		defer close(ch)
+		rediscover := time.NewTicker(or(c.opts.rediscoverInterval, time.Minute))
+		defer rediscover.Stop()
+		poll := time.NewTicker(c.pollInterval())
+		defer poll.Stop()
+
+		var (
+			lastTopo string
+			lastLib  *libraryState
+		)
+		for {
+			var evs []Event
+			select {
+			case <-ctx.Done():
+				return
+			case <-rediscover.C:
+				des, err := c.rediscover(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						c.logger.WarnContext(ctx, "Rediscovering devices", "err", err)
+					}
+					continue
+				}
+				for i := range des {
+					evs = append(evs, &des[i])
+				}
+			case <-poll.C:
+				groups, err := c.zoneGroups(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						c.logger.WarnContext(ctx, "Polling zone groups", "err", err)
+					}
+				} else if topo := topologyKey(groups); topo != lastTopo {
+					if lastTopo != "" {
+						evs = append(evs, &TopologyEvent{Groups: groups})
+					}
+					lastTopo = topo
+				}
+				lib, err := c.libraryState(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						c.logger.WarnContext(ctx, "Polling library state", "err", err)
+					}
+				} else {
+					if lastLib != nil {
+						if ev := lib.changes(*lastLib); ev != nil {
+							evs = append(evs, ev)
+						}
+					}
+					lastLib = &lib
+				}
+			}
+			for _, ev := range evs {
+				select {
+				case ch <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch
+}
+
+// rediscover does a full discovery, replaces the client's devices with the result,
+// and returns what changed.
+func (c *Client) rediscover(ctx context.Context) ([]DeviceEvent, error) {
+	locs, err := c.search(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fresh := newClient(nil)
+	fresh.opts, fresh.hc, fresh.logger = c.opts, c.hc, c.logger
+	fresh.addDevices(ctx, locs, nil) // errors are treated as the device being absent
+
+	// A device may have simply missed the search. Check directly on any that seem to be gone.
+	c.mu.Lock()
+	var missing []*url.URL
+	for _, dev := range c.devices {
+		if !fresh.hasDevice(dev.UDN) {
+			missing = append(missing, c.meta[dev.UDN].loc)
+		}
+	}
+	c.mu.Unlock()
+	fresh.addDevices(ctx, missing, nil)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var evs []DeviceEvent
+	known := make(map[string]bool)
+	for _, dev := range c.devices {
+		known[dev.UDN] = true
+		if !fresh.hasDevice(dev.UDN) {
+			evs = append(evs, DeviceEvent{
+				Type: DeviceRemoved,
+				UDN:  dev.UDN,
+				Name: dev.FriendlyName,
+				Zone: c.zoneOf(dev.UDN),
+			})
+		}
+	}
+	for _, dev := range fresh.devices {
+		if !known[dev.UDN] {
+			evs = append(evs, DeviceEvent{
+				Type: DeviceAdded,
+				UDN:  dev.UDN,
+				Name: dev.FriendlyName,
+				Zone: fresh.zoneOf(dev.UDN),
+			})
+		}
+	}
+	c.devices, c.zones, c.meta = fresh.devices, fresh.zones, fresh.meta
+	c.soapClients = make(map[string]*soap.SOAPClient) // devices may have moved
+	return evs, nil
+}
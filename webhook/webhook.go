@@ -0,0 +1,221 @@
+// Package webhook forwards the events of a Sonos system to webhooks.
+//
+// Each event is POSTed as JSON of the form
+//
+//	{"type": "transport", "time": "2024-05-01T12:00:00Z", "event": {...}}
+//
+// where the event is the JSON encoding of the sonos package's event type
+// (e.g. sonos.TransportEvent). If a secret is set, each request has a header
+//
+//	X-Sonos-Signature: sha256=<hex HMAC-SHA256 of the body>
+//
+// so that receivers can check that it came from the bridge.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dsymonds/sonos"
+)
+
+// Bridge forwards a Sonos system's events to webhook URLs.
+type Bridge struct {
+	Client *sonos.Client
+	URLs   []string
+	Secret []byte // if non-empty, requests are signed with it
+
+	HTTPClient *http.Client  // default http.DefaultClient
+	Attempts   int           // total attempts per delivery; default 3
+	Backoff    time.Duration // delay before the first retry, doubled for each subsequent one; default 1s
+}
+
+// Payload is the JSON body of each webhook request.
+type Payload struct {
+	Type  string      `json:"type"` // "device", "topology", "library", "transport", "track" or "volume"
+	Time  time.Time   `json:"time"`
+	Event sonos.Event `json:"event"`
+}
+
+// Run watches the Client, and the device of each of its zones,
+// and forwards their events until ctx is done.
+// Zones that appear later are watched once their devices are found.
+// Events are delivered in order; failed deliveries are logged to the Client's logger and dropped.
+func (b *Bridge) Run(ctx context.Context) error {
+	evs := make(chan sonos.Event)
+	var wg sync.WaitGroup
+	forward := func(ch <-chan sonos.Event) {
+		defer wg.Done()
+		for ev := range ch {
+			select {
+			case evs <- ev:
+			case <-ctx.Done():
+			}
+		}
+	}
+	wg.Add(1)
+	go forward(b.Client.Watch(ctx))
+
+	watching := make(map[string]context.CancelFunc) // by UDN
+	watchZones := func() {
+		zones, err := b.Client.Zones(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				b.Client.Logger().WarnContext(ctx, "Listing zones", "err", err)
+			}
+			return
+		}
+		for _, z := range zones {
+			d, err := b.Client.ZoneDevice(ctx, z.Name)
+			if err != nil || watching[d.UDN()] != nil {
+				continue
+			}
+			wctx, cancel := context.WithCancel(ctx)
+			watching[d.UDN()] = cancel
+			wg.Add(1)
+			go forward(d.Watch(wctx))
+		}
+	}
+	watchZones()
+	go func() {
+		wg.Wait()
+		close(evs)
+	}()
+
+	for ev := range evs {
+		if ctx.Err() != nil {
+			continue // drain
+		}
+		if de, ok := ev.(*sonos.DeviceEvent); ok {
+			if cancel := watching[de.UDN]; de.Type == sonos.DeviceRemoved && cancel != nil {
+				cancel()
+				delete(watching, de.UDN)
+			}
+			watchZones() // a zone may have a new device
+		}
+		if err := b.send(ctx, ev); err != nil {
+			b.Client.Logger().WarnContext(ctx, "Forwarding event", "type", eventType(ev), "err", err)
+		}
+	}
+	return ctx.Err()
+}
+
+// send delivers an event to every URL, concurrently.
+func (b *Bridge) send(ctx context.Context, ev sonos.Event) error {
+	body, err := json.Marshal(Payload{
+		Type:  eventType(ev),
+		Time:  time.Now().UTC(),
+		Event: ev,
+	})
+	if err != nil {
+		return err
+	}
+	errs := make([]error, len(b.URLs))
+	var wg sync.WaitGroup
+	for i, u := range b.URLs {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			errs[i] = b.deliver(ctx, u, body)
+		}(i, u)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliver POSTs the body to the URL, retrying failures.
+func (b *Bridge) deliver(ctx context.Context, url string, body []byte) error {
+	attempts := b.Attempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+	backoff := b.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	var err error
+	for attempt := 1; ; attempt++ {
+		var retry bool
+		retry, err = b.post(ctx, url, body)
+		if err == nil || !retry || attempt >= attempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff << (attempt - 1)):
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("posting to %s: %w", url, err)
+	}
+	return nil
+}
+
+// post makes a single request, and reports whether a failure is worth retrying.
+func (b *Bridge) post(ctx context.Context, url string, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(b.Secret) > 0 {
+		req.Header.Set("X-Sonos-Signature", "sha256="+Sign(b.Secret, body))
+	}
+	hc := b.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		// Client errors won't be fixed by trying again, except for rate limiting.
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return retry, fmt.Errorf("got HTTP %s", resp.Status)
+	}
+	return false, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body with the secret,
+// as sent in the X-Sonos-Signature header.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func eventType(ev sonos.Event) string {
+	switch ev.(type) {
+	case *sonos.DeviceEvent:
+		return "device"
+	case *sonos.TopologyEvent:
+		return "topology"
+	case *sonos.LibraryEvent:
+		return "library"
+	case *sonos.TransportEvent:
+		return "transport"
+	case *sonos.TrackEvent:
+		return "track"
+	case *sonos.VolumeEvent:
+		return "volume"
+	}
+	return "unknown"
+}
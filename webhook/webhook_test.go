@@ -0,0 +1,145 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dsymonds/sonos"
+	"github.com/dsymonds/sonos/sonostest"
+)
+
+// request is a request received by a webhook.
+type request struct {
+	signature string
+	payload   map[string]json.RawMessage
+	body      []byte
+}
+
+func TestBridge(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fake := sonostest.NewDevice("Kitchen")
+	defer fake.Close()
+	c, err := sonos.NewClientFromIPs(ctx, nil, sonos.WithPollInterval(10*time.Millisecond), sonos.WithRediscoverInterval(time.Hour))
+	if err != nil {
+		t.Fatalf("NewClientFromIPs: %v", err)
+	}
+	if err := c.AddDeviceByURL(ctx, fake.Location()); err != nil {
+		t.Fatalf("AddDeviceByURL: %v", err)
+	}
+
+	reqs := make(chan request, 100)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var p map[string]json.RawMessage
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Errorf("bad payload %q: %v", body, err)
+		}
+		reqs <- request{signature: r.Header.Get("X-Sonos-Signature"), payload: p, body: body}
+	}))
+	defer srv.Close()
+
+	secret := []byte("s3cret")
+	b := &Bridge{Client: c, URLs: []string{srv.URL}, Secret: secret}
+	done := make(chan error)
+	go func() { done <- b.Run(ctx) }()
+
+	// next returns the next request with the given event type.
+	next := func(typ string) request {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case req := <-reqs:
+				if string(req.payload["type"]) == `"`+typ+`"` {
+					return req
+				}
+			case <-timeout:
+				t.Fatalf("no %s event delivered", typ)
+			}
+		}
+	}
+
+	req := next("volume")
+	if want := "sha256=" + Sign(secret, req.body); req.signature != want {
+		t.Errorf("signature is %q, want %q", req.signature, want)
+	}
+	var vol sonos.VolumeEvent
+	if err := json.Unmarshal(req.payload["event"], &vol); err != nil {
+		t.Fatalf("bad volume event %s: %v", req.payload["event"], err)
+	}
+	if vol.Volume != 20 {
+		t.Errorf("volume event has volume %d, want 20", vol.Volume)
+	}
+
+	d, err := c.ZoneDevice(ctx, "Kitchen")
+	if err != nil {
+		t.Fatalf("ZoneDevice: %v", err)
+	}
+	if err := d.Play(ctx); err != nil {
+		t.Fatalf("Play: %v", err)
+	}
+	for {
+		req = next("transport")
+		var te sonos.TransportEvent
+		if err := json.Unmarshal(req.payload["event"], &te); err != nil {
+			t.Fatalf("bad transport event %s: %v", req.payload["event"], err)
+		}
+		if te.State == sonos.Playing {
+			break
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Run = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Run did not return after cancellation")
+	}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	tests := []struct {
+		statuses []int // responses, in order; then 200
+		attempts int
+		ok       bool
+	}{
+		{nil, 1, true},
+		{[]int{503}, 2, true},
+		{[]int{503, 429}, 3, true},
+		{[]int{503, 503, 503}, 3, false},
+		{[]int{400}, 1, false},
+	}
+	for _, tc := range tests {
+		var mu sync.Mutex
+		n := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if n < len(tc.statuses) {
+				w.WriteHeader(tc.statuses[n])
+			}
+			n++
+		}))
+		b := &Bridge{Backoff: time.Millisecond}
+		err := b.deliver(context.Background(), srv.URL, []byte(`{}`))
+		srv.Close()
+		if (err == nil) != tc.ok {
+			t.Errorf("with responses %v, deliver = %v, want ok %t", tc.statuses, err, tc.ok)
+		}
+		if n != tc.attempts {
+			t.Errorf("with responses %v, made %d attempts, want %d", tc.statuses, n, tc.attempts)
+		}
+	}
+}
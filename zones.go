@@ -0,0 +1,173 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Zone describes a zone (room) known to a Client.
+type Zone struct {
+	Name        string
+	NumDevices  int
+	Coordinator string // UUID of the device that leads the zone (e.g. the left of a stereo pair)
+	Icon        string // e.g. "x-rincon-roomicon:living"
+	Generation  Generation
+}
+
+// Zones returns the zones known to the client, sorted by name.
+func (c *Client) Zones(ctx context.Context) ([]Zone, error) {
+	c.mu.Lock()
+	var zones []Zone
+	for name, devs := range c.zones {
+		z := Zone{
+			Name:       name,
+			NumDevices: len(devs),
+		}
+		if m := c.meta[devs[0].UDN]; m != nil {
+			z.Generation = m.desc.generation()
+			z.Icon = m.icon
+		}
+		zones = append(zones, z)
+	}
+	c.mu.Unlock()
+	sort.Slice(zones, func(i, j int) bool { return zones[i].Name < zones[j].Name })
+	if len(zones) == 0 {
+		return nil, nil
+	}
+
+	groups, err := c.zoneGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	leaders := make(map[string]ZoneGroupMember) // by zone name
+	for _, g := range groups {
+		for _, m := range g.Members {
+			if !m.Invisible {
+				leaders[m.ZoneName] = m
+			}
+		}
+	}
+	for i := range zones {
+		m := leaders[zones[i].Name]
+		zones[i].Coordinator = m.UUID
+		if m.Icon != "" {
+			zones[i].Icon = m.Icon
+		}
+	}
+	return zones, nil
+}
+
+// zoneGroups returns the household's zone groups, as seen by any device.
+func (c *Client) zoneGroups(ctx context.Context) ([]ZoneGroup, error) {
+	var lastErr error
+	for _, d := range c.Devices() {
+		if len(d.dev.FindService(ZoneGroupTopologyService)) == 0 {
+			continue
+		}
+		groups, err := d.ZoneGroups(ctx)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return groups, nil
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no device provides zone group topology")
+	}
+	return nil, lastErr
+}
+
+// Devices returns all the devices known to the client.
+func (c *Client) Devices() []*Device {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	devs := make([]*Device, len(c.devices))
+	for i, dev := range c.devices {
+		devs[i] = &Device{
+			c:   c,
+			dev: dev,
+		}
+	}
+	return devs
+}
+
+// Apply runs f on the device for each of the named zones, concurrently.
+// If zones is nil, f is run for every zone.
+// The returned error combines the errors from every zone that failed.
+func (c *Client) Apply(ctx context.Context, zones []string, f func(*Device) error) error {
+	if zones == nil {
+		c.mu.Lock()
+		for name := range c.zones {
+			zones = append(zones, name)
+		}
+		c.mu.Unlock()
+		sort.Strings(zones)
+	}
+
+	errs := make([]error, len(zones))
+	var wg sync.WaitGroup
+	for i, zone := range zones {
+		wg.Add(1)
+		go func(i int, zone string) {
+			defer wg.Done()
+			d, err := c.ZoneDevice(ctx, zone)
+			if err == nil {
+				err = f(d)
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("zone %q: %w", zone, err)
+			}
+		}(i, zone)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// EachZone runs f on the device for every zone, concurrently.
+// It is shorthand for Apply with nil zones.
+func (c *Client) EachZone(ctx context.Context, f func(*Device) error) error {
+	return c.Apply(ctx, nil, f)
+}
+
+// PlayEverywhere plays the same URI in each of the named zones, without grouping them.
+// Every zone is first switched to the URI, and only once they all have been
+// are they sent Play, concurrently, so that playback starts at close to the same time.
+// The zones are not kept in sync afterwards; group them with Device.Join for that.
+// If any zone cannot be switched to the URI, none are played.
+func (c *Client) PlayEverywhere(ctx context.Context, zones []string, uri string) error {
+	err := c.Apply(ctx, zones, func(d *Device) error {
+		if err := d.setAVTransportURI(ctx, uri, ""); err != nil {
+			return fmt.Errorf("setting URI: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return c.Apply(ctx, zones, func(d *Device) error { return d.Play(ctx) })
+}
+
+// WaitForZone waits for a zone with the given name to appear,
+// searching the network repeatedly, and returns its device.
+// It is useful at boot, when players may not yet have joined the network.
+// It gives up when ctx is done.
+func (c *Client) WaitForZone(ctx context.Context, name string) (*Device, error) {
+	for {
+		if d, err := c.ZoneDevice(ctx, name); err == nil {
+			return d, nil
+		}
+		// Searching takes a while itself, so only pause if it fails,
+		// such as when the network is not yet up.
+		if _, err := c.rediscover(ctx); err != nil {
+			if ctx.Err() == nil {
+				c.logger.WarnContext(ctx, "Rediscovering devices", "err", err)
+			}
+			if err := sleepCtx(ctx, c.pollInterval()); err != nil {
+				return nil, fmt.Errorf("waiting for zone %q: %w", name, err)
+			}
+		}
+	}
+}